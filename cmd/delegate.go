@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/seal-io/tap/pkg/terraform"
 	"github.com/seal-io/tap/pkg/workingdir"
 	"github.com/seal-io/tap/utils/set"
 	"github.com/seal-io/tap/utils/version"
@@ -15,8 +16,13 @@ import (
 )
 
 func Delegate(ctx context.Context, cmd string, args []string) (err error) {
+	// Run the tap subcommands without delegating.
+	if IsTapCommand(args) {
+		return Tap(ctx, cmd, args)
+	}
+
 	// Setup working dir.
-	args, err = workingdir.Setup(args)
+	args, err = workingdir.Setup(terraform.FlavorOf(cmd), args)
 	if err != nil {
 		return fmt.Errorf("error setting up the working directory: %w", err)
 	}
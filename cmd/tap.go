@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/seal-io/tap/pkg/tap"
+	"github.com/seal-io/tap/pkg/terraform"
+	"github.com/seal-io/tap/pkg/workingdir"
+)
+
+// tapCommand is the name of the wrapper-level command,
+// whose subcommands never invoke the delegated CLI.
+const tapCommand = "tap"
+
+const tapUsage = `Usage: tf [global options] tap <subcommand> [options]
+
+  Run the TAP subcommands without invoking Terraform or OpenTofu.
+
+Subcommands:
+  render    Print the patched configuration to stdout.
+  export    Write a standalone working copy with the patched configuration.
+  diff      Show the changes of each object made by the tap configuration.
+  validate  Check the tap configuration against the configuration.
+
+Options:
+  -tap-var 'name=value'  Set a value for one of the tap variables,
+                         which can be given multiple times.
+`
+
+// IsTapCommand returns true if the given arguments run a tap subcommand,
+// that is, the first argument after the global options and the -tap-var options is "tap".
+func IsTapCommand(args []string) bool {
+	// Skip the -tap-var options, whose values might be given as separate arguments,
+	// the invalid ones are reported by Tap.
+	if _, as, err := workingdir.Vars(args); err == nil {
+		args = as
+	}
+
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			return arg == tapCommand
+		}
+	}
+
+	return false
+}
+
+// Tap runs the tap subcommand of the given arguments,
+// and prints the error to stderr if failed.
+//
+// The given executable binary path can be empty,
+// since the tap subcommands never invoke the delegated CLI.
+func Tap(ctx context.Context, bin string, args []string) error {
+	err := runTap(ctx, bin, args)
+	if err != nil && !errors.Is(err, flag.ErrHelp) {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	}
+
+	return err
+}
+
+func runTap(ctx context.Context, bin string, args []string) error {
+	workingDir, args, err := workingdir.Dir(args)
+	if err != nil {
+		return err
+	}
+
+	// Detect the flavor from the working dir first,
+	// fallback to the flavor of the executable binary if found.
+	flavor := terraform.FlavorOfDir(workingDir)
+	if flavor == "" && bin != "" {
+		flavor = terraform.FlavorOf(bin)
+	}
+
+	vars, args, err := workingdir.Vars(args)
+	if err != nil {
+		return err
+	}
+
+	opts := []tap.LoadOption{tap.WithVariables(vars)}
+
+	// Drop the global options and the tap command.
+	for i := range args {
+		if args[i] == tapCommand {
+			args = args[i+1:]
+			break
+		}
+	}
+
+	if len(args) == 0 {
+		_, _ = fmt.Fprint(os.Stderr, tapUsage)
+		return errors.New("no tap subcommand specified")
+	}
+
+	switch sub, args := args[0], args[1:]; sub {
+	case "render":
+		return tapRender(ctx, flavor, workingDir, args, opts)
+	case "export":
+		return tapExport(ctx, flavor, workingDir, args, opts)
+	case "diff":
+		return tapDiff(ctx, flavor, workingDir, args, opts)
+	case "validate":
+		return tapValidate(ctx, flavor, workingDir, args, opts)
+	case "-h", "-help", "--help", "help":
+		_, _ = fmt.Fprint(os.Stderr, tapUsage)
+		return nil
+	default:
+		_, _ = fmt.Fprint(os.Stderr, tapUsage)
+		return fmt.Errorf("unknown tap subcommand %q", sub)
+	}
+}
+
+func tapRender(_ context.Context, flavor terraform.Flavor, workingDir string, args []string, opts []tap.LoadOption) error {
+	fs := flag.NewFlagSet("render", flag.ContinueOnError)
+	fs.Usage = func() {
+		_, _ = fmt.Fprint(fs.Output(), "Usage: tf [global options] tap render\n\n"+
+			"  Print the configuration patched by the tap configuration to stdout,\n"+
+			"  the output syntax follows the tap configuration or TAP_OUTPUT_SYNTAX.\n")
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	return workingdir.Render(flavor, workingDir, os.Stdout, opts...)
+}
+
+func tapExport(_ context.Context, flavor terraform.Flavor, workingDir string, args []string, opts []tap.LoadOption) error {
+	var out string
+
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	fs.StringVar(&out, "out", "", "The directory to write the working copy, must be empty or not exist.")
+	fs.Usage = func() {
+		_, _ = fmt.Fprint(fs.Output(), "Usage: tf [global options] tap export -out=DIR\n\n"+
+			"  Write a standalone working copy with the patched configuration into DIR,\n"+
+			"  which can be used without TAP.\n\nOptions:\n")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if out == "" {
+		fs.Usage()
+		return errors.New("the -out option is required")
+	}
+
+	return workingdir.Export(flavor, workingDir, out, opts...)
+}
+
+func tapDiff(_ context.Context, flavor terraform.Flavor, workingDir string, args []string, opts []tap.LoadOption) error {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	fs.Usage = func() {
+		_, _ = fmt.Fprint(fs.Output(), "Usage: tf [global options] tap diff\n\n"+
+			"  Show the changes of each object made by the tap configuration,\n"+
+			"  each hunk is annotated with the operations that cause it.\n")
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	return workingdir.Diff(flavor, workingDir, os.Stdout, opts...)
+}
+
+func tapValidate(_ context.Context, flavor terraform.Flavor, workingDir string, args []string, opts []tap.LoadOption) error {
+	var asJSON bool
+
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	fs.BoolVar(&asJSON, "json", false, "Produce output in a machine-readable JSON format.")
+	fs.Usage = func() {
+		_, _ = fmt.Fprint(fs.Output(), "Usage: tf [global options] tap validate [-json]\n\n"+
+			"  Check the tap configuration against the configuration,\n"+
+			"  and report every problem with the source snippet.\n\nOptions:\n")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	return workingdir.Validate(flavor, workingDir, os.Stdout, asJSON, opts...)
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsTapCommand(t *testing.T) {
+	testCases := []struct {
+		given    []string
+		expected bool
+	}{
+		{
+			given:    []string{"tap", "render"},
+			expected: true,
+		},
+		{
+			given:    []string{"-chdir=foo", "tap", "validate"},
+			expected: true,
+		},
+		{
+			given:    []string{"-tap-var", "env=prod", "tap", "render"},
+			expected: true,
+		},
+		{
+			given:    []string{"-tap-var=env=prod", "tap", "render"},
+			expected: true,
+		},
+		{
+			given:    []string{"--tap-var", "env=prod", "-chdir=foo", "tap", "diff"},
+			expected: true,
+		},
+		{
+			given:    []string{"-tap-var=env", "tap", "render"},
+			expected: true,
+		},
+		{
+			given:    []string{"-tap-var", "env=prod", "plan"},
+			expected: false,
+		},
+		{
+			given:    []string{"plan", "tap"},
+			expected: false,
+		},
+		{
+			given:    []string{"-tap-var"},
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, IsTapCommand(tc.given), tc.given)
+	}
+}
+
+func TestTap_renderWithoutBinary(t *testing.T) {
+	// The flavor is detected by the .tofu files without the executable binary,
+	// so that the .tofu files are loaded and the encryption block is kept.
+	t.Setenv("PATH", "")
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("failed to create pipe: %v", err)
+	}
+
+	stdout := os.Stdout
+	os.Stdout = w
+
+	err = Tap(context.Background(), "", []string{"-chdir=" + filepath.Join("testdata", "render_tofu"), "tap", "render"})
+
+	os.Stdout = stdout
+	_ = w.Close()
+
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	out, err := io.ReadAll(r)
+	if assert.NoError(t, err) {
+		assert.Contains(t, string(out), "encryption {")
+		assert.Contains(t, string(out), `namespace = "default"`)
+	}
+}
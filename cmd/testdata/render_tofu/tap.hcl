@@ -0,0 +1,8 @@
+tap {}
+
+resource "kubernetes_deployment" {
+  set {
+    path  = "/metadata/0/namespace"
+    value = "default"
+  }
+}
@@ -36,8 +36,14 @@ func main() {
 	}
 
 	if err != nil {
-		log.Printf("[ERROR] Could not load terraform executable binary\n")
-		os.Exit(1)
+		// The tap subcommands do not need the executable binary,
+		// the flavor is detected from the working directory instead.
+		if !cmd.IsTapCommand(os.Args[1:]) {
+			log.Printf("[ERROR] Could not load terraform executable binary\n")
+			os.Exit(1)
+		}
+
+		tfBin = ""
 	}
 
 	err = cmd.Delegate(signals.SetupSignalHandler(), tfBin, os.Args[1:])
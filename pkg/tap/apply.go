@@ -1,57 +1,489 @@
 package tap
 
 import (
+	"bytes"
 	"fmt"
+	"path"
+	"path/filepath"
 
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	hcljson "github.com/hashicorp/hcl/v2/json"
+	"github.com/hashicorp/terraform/addrs"
 	"github.com/hashicorp/terraform/configs"
+	"github.com/zclconf/go-cty/cty"
+	"golang.org/x/exp/maps"
 	"golang.org/x/exp/slices"
+
+	"github.com/seal-io/tap/pkg/terraform"
 )
 
 // Apply applies the tap configuration to the Terraform configuration.
 func Apply(tfCfg *configs.Config, cfg *Config) (*configs.Config, error) {
+	tfCfg, _, err := ApplyWithReport(tfCfg, cfg)
+	return tfCfg, err
+}
+
+// ApplyWithReport is the same as Apply, but also returns the report of the operation outcomes,
+// the report is returned even if applying fails.
+func ApplyWithReport(tfCfg *configs.Config, cfg *Config) (*configs.Config, *Report, error) {
+	report := &Report{}
+	defer report.sort()
+
 	if cfg == nil {
-		return tfCfg, nil
+		return tfCfg, report, nil
 	}
 
 	for i := range cfg.Patches {
 		p := cfg.Patches[i]
 
-		// Select typed resources.
-		originalRess := tfCfg.Module.ManagedResources
-		if p.ResourceMode == "data" {
-			originalRess = tfCfg.Module.DataResources
+		objects, err := applyPatch(tfCfg.Module, &p, cfg.PathSyntax, report)
+		if err != nil {
+			return nil, report, err
+		}
+
+		// Check the matches.
+		if err := report.check(&p, objects, cfg.Strict); err != nil {
+			return nil, report, err
 		}
+	}
+
+	return tfCfg, report, nil
+}
 
-		if len(originalRess) == 0 {
+// applyPatch applies the given patch to the given module,
+// and records the outcomes into the given report,
+// which also detects the conflicts with the previous patches.
+//
+// The number of the objects selected by the patch is returned,
+// including the objects where no operation applies.
+func applyPatch(m *configs.Module, p *Patch, pathSyntax string, report *Report) (int, error) {
+	var (
+		objects int
+		err     error
+	)
+
+	switch p.ResourceMode {
+	default:
+		objects, err = applyResources(m, p, pathSyntax, report)
+	case "variable":
+		objects, err = applyVariables(m, p, pathSyntax, report)
+	case "output":
+		objects, err = applyOutputs(m, p, pathSyntax, report)
+	case "locals":
+		objects, err = applyLocals(m, p, pathSyntax, report)
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("error operating on %s blocks: %w", p.ResourceMode, err)
+	}
+
+	return objects, nil
+}
+
+func applyResources(m *configs.Module, p *Patch, pathSyntax string, report *Report) (int, error) {
+	// Select typed resources.
+	originalRess := m.ManagedResources
+	if p.ResourceMode == "data" {
+		originalRess = m.DataResources
+	}
+
+	if len(originalRess) == 0 {
+		return 0, nil
+	}
+
+	// Select resources.
+	var (
+		selectedBodies = make(TerraformBodies)
+		objects        = make(map[string]cty.Value)
+		snapshots      = make(map[string][]byte)
+	)
+
+	for _, rn := range sortedKeys(originalRess) {
+		r := originalRess[rn]
+		obj := resourceObject(p.ResourceMode, r.Type, r.Name, rn, r.DeclRange)
+
+		selected, diags := p.selects(r.Type, r.Name, obj)
+		if diags.HasErrors() {
+			return 0, fmt.Errorf("error selecting %s: %w", rn, diags)
+		}
+
+		if !selected {
 			continue
 		}
 
-		// Select resources.
-		selectedRess := make(TerraformResources)
+		selectedBodies[rn] = r.Config
+		objects[rn] = obj
+		snapshots[rn] = bodyBytes(r.Config)
+	}
 
-		for rn := range originalRess {
-			if !slices.Contains(p.ResourceTypes, originalRess[rn].Type) {
-				continue
-			}
+	// Operate.
+	err := operate(selectedBodies, objects, p, pathSyntax, report)
+	if err != nil {
+		return 0, err
+	}
 
-			if len(p.ResourceNames) != 0 &&
-				!slices.Contains(p.ResourceNames, originalRess[rn].Name) {
-				continue
+	// Validate the changed resources in the address order,
+	// and refresh the meta-arguments.
+	for _, rn := range sortedKeys(selectedBodies) {
+		if bytes.Equal(snapshots[rn], bodyBytes(originalRess[rn].Config)) {
+			continue
+		}
+
+		nr, err := validateResource(m, originalRess[rn])
+		if err != nil {
+			return 0, fmt.Errorf("error validating %s: %w", rn, err)
+		}
+
+		originalRess[rn] = nr
+
+		// Refresh the scoped data of the check block as well.
+		if c, ok := nr.Container.(*configs.Check); ok {
+			c.DataResource = nr
+		}
+	}
+
+	return len(selectedBodies), nil
+}
+
+// validateResource decodes the patched body of the given resource again,
+// returns a new resource with the refreshed meta-arguments,
+// e.g. count, for_each, depends_on, provider and lifecycle.
+func validateResource(m *configs.Module, r *configs.Resource) (*configs.Resource, error) {
+	b, ok := r.Config.(*hclsyntax.Body)
+	if !ok {
+		return nil, fmt.Errorf("invalid body type: %T", r.Config)
+	}
+
+	// Reveal the meta-arguments hidden by the previous decoding.
+	blk := &hcl.Block{
+		Labels: []string{r.Type, r.Name},
+		Body: &hclsyntax.Body{
+			Attributes: b.Attributes,
+			Blocks:     b.Blocks,
+			SrcRange:   b.SrcRange,
+			EndRange:   b.EndRange,
+		},
+		DefRange:    r.DeclRange,
+		TypeRange:   r.DeclRange,
+		LabelRanges: []hcl.Range{r.TypeRange, r.DeclRange},
+	}
+
+	if isJSONSyntax(r.DeclRange) {
+		jb, err := terraform.ToJSONBody(b, r.DeclRange.Filename)
+		if err != nil {
+			return nil, err
+		}
+
+		blk.Body = jb
+	}
+
+	var (
+		nr    *configs.Resource
+		diags hcl.Diagnostics
+	)
+
+	switch r.Mode {
+	default:
+		blk.Type = "resource"
+		nr, diags = configs.DecodeResourceBlock(blk, false)
+	case addrs.DataResourceMode:
+		blk.Type = "data"
+		nr, diags = configs.DecodeDataBlock(blk, false, r.Container != nil)
+	}
+
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	nr.Config = convertJSONBody(nr.Config)
+	nr.Container = r.Container
+	nr.Provider = r.Provider
+
+	if nr.ProviderConfigRef != nil {
+		if nr.ProviderConfigRef.Alias != "" {
+			pk := nr.ProviderConfigAddr().StringCompact()
+			if _, exist := m.ProviderConfigs[pk]; !exist {
+				return nil, hcl.Diagnostics{
+					{
+						Severity: hcl.DiagError,
+						Summary:  "Provider configuration not present",
+						Detail:   fmt.Sprintf("The provider configuration %q is not declared in the root module.", pk),
+						Subject:  nr.ProviderConfigRef.NameRange.Ptr(),
+					},
+				}
 			}
+		}
+
+		nr.Provider = m.ProviderForLocalConfig(nr.ProviderConfigAddr())
+	}
 
-			selectedRess[rn] = originalRess[rn]
+	return nr, nil
+}
+
+func applyVariables(m *configs.Module, p *Patch, pathSyntax string, report *Report) (int, error) {
+	// Select variables.
+	var (
+		selectedVars   = make(map[string]*configs.Variable)
+		selectedBodies = make(TerraformBodies)
+		objects        = make(map[string]cty.Value)
+		snapshots      = make(map[string][]byte)
+	)
+
+	for _, vn := range sortedKeys(m.Variables) {
+		v := m.Variables[vn]
+		if v.Config == nil {
+			continue
+		}
+
+		addr := v.Addr().String()
+		obj := resourceObject(p.ResourceMode, "", vn, addr, v.DeclRange)
+
+		selected, diags := p.selects("", vn, obj)
+		if diags.HasErrors() {
+			return 0, fmt.Errorf("error selecting %s: %w", addr, diags)
+		}
+
+		if !selected {
+			continue
 		}
 
-		// Operate.
-		operatedRess, err := Operate(selectedRess, &p, cfg.PathSyntax)
+		selectedVars[addr] = v
+		selectedBodies[addr] = v.Config
+		objects[addr] = obj
+		snapshots[addr] = bodyBytes(v.Config)
+	}
+
+	// Operate.
+	err := operate(selectedBodies, objects, p, pathSyntax, report)
+	if err != nil {
+		return 0, err
+	}
+
+	// Validate the changed variables in the address order.
+	for _, addr := range sortedKeys(selectedVars) {
+		v := selectedVars[addr]
+
+		if bytes.Equal(snapshots[addr], bodyBytes(v.Config)) {
+			continue
+		}
+
+		body, err := decodableBody(v.Config, v.DeclRange)
 		if err != nil {
-			return nil, fmt.Errorf("erorr operating on resources: %w", err)
+			return 0, fmt.Errorf("error validating %s: %w", addr, err)
 		}
 
-		for rn := range operatedRess {
-			originalRess[rn] = operatedRess[rn]
+		nv, diags := configs.DecodeVariableBlock(&hcl.Block{
+			Type:        "variable",
+			Labels:      []string{v.Name},
+			Body:        body,
+			DefRange:    v.DeclRange,
+			LabelRanges: []hcl.Range{v.DeclRange},
+		}, false)
+		if diags.HasErrors() {
+			return 0, fmt.Errorf("error validating %s: %w", addr, diags)
 		}
+
+		nv.Config = convertJSONBody(nv.Config)
+		m.Variables[v.Name] = nv
 	}
 
-	return tfCfg, nil
+	return len(selectedBodies), nil
+}
+
+func applyOutputs(m *configs.Module, p *Patch, pathSyntax string, report *Report) (int, error) {
+	// Select outputs.
+	var (
+		selectedOutputs = make(map[string]*configs.Output)
+		selectedBodies  = make(TerraformBodies)
+		objects         = make(map[string]cty.Value)
+		snapshots       = make(map[string][]byte)
+	)
+
+	for _, on := range sortedKeys(m.Outputs) {
+		o := m.Outputs[on]
+		if o.Config == nil {
+			continue
+		}
+
+		addr := o.Addr().String()
+		obj := resourceObject(p.ResourceMode, "", on, addr, o.DeclRange)
+
+		selected, diags := p.selects("", on, obj)
+		if diags.HasErrors() {
+			return 0, fmt.Errorf("error selecting %s: %w", addr, diags)
+		}
+
+		if !selected {
+			continue
+		}
+
+		selectedOutputs[addr] = o
+		selectedBodies[addr] = o.Config
+		objects[addr] = obj
+		snapshots[addr] = bodyBytes(o.Config)
+	}
+
+	// Operate.
+	err := operate(selectedBodies, objects, p, pathSyntax, report)
+	if err != nil {
+		return 0, err
+	}
+
+	// Validate the changed outputs in the address order.
+	for _, addr := range sortedKeys(selectedOutputs) {
+		o := selectedOutputs[addr]
+
+		if bytes.Equal(snapshots[addr], bodyBytes(o.Config)) {
+			continue
+		}
+
+		body, err := decodableBody(o.Config, o.DeclRange)
+		if err != nil {
+			return 0, fmt.Errorf("error validating %s: %w", addr, err)
+		}
+
+		no, diags := configs.DecodeOutputBlock(&hcl.Block{
+			Type:        "output",
+			Labels:      []string{o.Name},
+			Body:        body,
+			DefRange:    o.DeclRange,
+			LabelRanges: []hcl.Range{o.DeclRange},
+		}, false)
+		if diags.HasErrors() {
+			return 0, fmt.Errorf("error validating %s: %w", addr, diags)
+		}
+
+		no.Config = convertJSONBody(no.Config)
+		m.Outputs[o.Name] = no
+	}
+
+	return len(selectedBodies), nil
+}
+
+func applyLocals(m *configs.Module, p *Patch, pathSyntax string, report *Report) (int, error) {
+	// Gather all locals into one body,
+	// so that the patch can operate them as attributes.
+	var (
+		body = &hclsyntax.Body{
+			Attributes: make(hclsyntax.Attributes, len(m.Locals)),
+		}
+		snapshots = make(map[string][]byte, len(m.Locals))
+	)
+
+	for ln, l := range m.Locals {
+		expr, ok := l.Expr.(hclsyntax.Expression)
+		if !ok {
+			continue
+		}
+
+		body.Attributes[ln] = &hclsyntax.Attribute{
+			Name:      ln,
+			Expr:      expr,
+			SrcRange:  l.DeclRange,
+			NameRange: l.DeclRange,
+		}
+		snapshots[ln] = exprBytes(expr)
+	}
+
+	// Select locals.
+	obj := resourceObject(p.ResourceMode, "", "", "locals", hcl.Range{})
+
+	selected, diags := p.selects("", "", obj)
+	if diags.HasErrors() {
+		return 0, fmt.Errorf("error selecting locals: %w", diags)
+	}
+
+	if !selected {
+		return 0, nil
+	}
+
+	// Operate.
+	err := operate(TerraformBodies{"locals": body}, map[string]cty.Value{"locals": obj}, p, pathSyntax, report)
+	if err != nil {
+		return 0, err
+	}
+
+	// Write back the changed locals.
+	for ln := range snapshots {
+		if _, exist := body.Attributes[ln]; !exist {
+			delete(m.Locals, ln)
+		}
+	}
+
+	for ln, attr := range body.Attributes {
+		if snapshot, exist := snapshots[ln]; exist && bytes.Equal(snapshot, exprBytes(attr.Expr)) {
+			continue
+		}
+
+		m.Locals[ln] = &configs.Local{
+			Name:      ln,
+			Expr:      attr.Expr,
+			DeclRange: attr.SrcRange,
+		}
+	}
+
+	return 1, nil
+}
+
+// decodableBody returns the body to decode again,
+// the body declared in JSON syntax is converted back,
+// since it has been converted into attributes only during loading.
+func decodableBody(body hcl.Body, declRange hcl.Range) (hcl.Body, error) {
+	b, ok := body.(*hclsyntax.Body)
+	if !ok || !isJSONSyntax(declRange) {
+		return body, nil
+	}
+
+	return terraform.ToJSONBody(b, declRange.Filename)
+}
+
+// convertJSONBody converts the decoded body in JSON syntax into HCL native syntax,
+// so that it can be patched again.
+func convertJSONBody(body hcl.Body) hcl.Body {
+	if sb, ok := hcljson.ToSyntaxBody(body); ok {
+		return sb
+	}
+
+	return body
+}
+
+// isJSONSyntax returns true if the given range is declared in a JSON file.
+func isJSONSyntax(r hcl.Range) bool {
+	return filepath.Ext(r.Filename) == ".json"
+}
+
+// sortedKeys returns the keys of the given map in order.
+func sortedKeys[T any](m map[string]T) []string {
+	ks := maps.Keys(m)
+	slices.Sort(ks)
+
+	return ks
+}
+
+// matchNames returns true if the given name matches any of the given glob patterns.
+func matchNames(patterns []string, name string) bool {
+	for i := range patterns {
+		if ok, _ := path.Match(patterns[i], name); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// bodyBytes returns the formatted bytes of the given hcl.Body,
+// which is used to detect whether the body has been changed.
+func bodyBytes(body hcl.Body) []byte {
+	f := hclwrite.NewEmptyFile()
+	f.Body().AppendHCLBody(body)
+
+	return f.Bytes()
+}
+
+// exprBytes returns the formatted bytes of the given hclsyntax.Expression,
+// which is used to detect whether the expression has been changed.
+func exprBytes(expr hclsyntax.Expression) []byte {
+	return hclwrite.TokensForExpression(expr).Bytes()
 }
@@ -2,8 +2,10 @@ package tap
 
 import (
 	"bytes"
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -34,14 +36,24 @@ func TestApply(t *testing.T) {
 			continue
 		}
 
-		tfCfg, err = Apply(tfCfg, cfg)
+		tfCfg, report, err := ApplyWithReport(tfCfg, cfg)
+		if strings.HasPrefix(tc.Name(), "invalid_") {
+			assert.Errorf(t, err, "error appling %s", tc.Name())
+			continue
+		}
+
 		if !assert.NoErrorf(t, err, "error appling %s", tc.Name()) {
 			continue
 		}
 
+		var opts []terraform.WriteOption
+		if cfg.Provenance {
+			opts = append(opts, terraform.WithAnnotations(report.Annotations()))
+		}
+
 		var actualBuff bytes.Buffer
 
-		err = terraform.Write(tfCfg, &actualBuff)
+		err = terraform.Write(tfCfg, &actualBuff, opts...)
 		if !assert.NoErrorf(t, err, "terraform write %s", tc.Name()) {
 			continue
 		}
@@ -52,5 +64,16 @@ func TestApply(t *testing.T) {
 		}
 
 		assert.Equal(t, string(expectedBytes), actualBuff.String(), "apply %s", tc.Name())
+
+		// Compare the report if expected.
+		expectedBytes, err = os.ReadFile(filepath.Join(testCasesDataDir, tc.Name(), "expected", "tap-report.json"))
+		if err != nil {
+			continue
+		}
+
+		actualBytes, err := json.MarshalIndent(report, "", "  ")
+		if assert.NoErrorf(t, err, "error marshaling report %s", tc.Name()) {
+			assert.JSONEq(t, string(expectedBytes), string(actualBytes), "report %s", tc.Name())
+		}
 	}
 }
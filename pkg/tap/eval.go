@@ -0,0 +1,363 @@
+package tap
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/ext/typeexpr"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/terraform/lang"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	"golang.org/x/exp/maps"
+
+	"github.com/seal-io/tap/utils/pointer"
+)
+
+// EnvVarPrefix is the prefix of the environment variables to assign the tap variables,
+// e.g. TAP_VAR_env assigns the tap variable "env".
+const EnvVarPrefix = "TAP_VAR_"
+
+// buildEvalContext builds the hcl.EvalContext from the variable and locals blocks of the given tap block body,
+// returns the remaining body to decode with the context.
+//
+// The context exposes the tap variables as var.<name>, the tap locals as local.<name>,
+// the environment variables as env.<name>, the selected workspace as terraform.workspace,
+// and the Terraform functions, the file functions read the files relative to the given dir.
+func buildEvalContext(body hcl.Body, dir string, vars map[string]string) (*hcl.EvalContext, hcl.Body, hcl.Diagnostics) {
+	bc, remain, diags := body.PartialContent(&hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{
+			{
+				Type:       "variable",
+				LabelNames: []string{"name"},
+			},
+			{
+				Type: "locals",
+			},
+		},
+	})
+	if diags.HasErrors() {
+		return nil, nil, diags
+	}
+
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"var":   cty.EmptyObjectVal,
+			"local": cty.EmptyObjectVal,
+			"env":   envValue(),
+			"terraform": cty.ObjectVal(map[string]cty.Value{
+				"workspace": cty.StringVal(workspace(dir)),
+			}),
+		},
+		Functions: (&lang.Scope{BaseDir: dir}).Functions(),
+	}
+
+	varVals, vDiags := buildVariables(bc.Blocks.OfType("variable"), vars)
+	diags = diags.Extend(vDiags)
+
+	if len(varVals) != 0 {
+		ctx.Variables["var"] = cty.ObjectVal(varVals)
+	}
+
+	diags = diags.Extend(buildLocals(bc.Blocks.OfType("locals"), ctx))
+
+	return ctx, remain, diags
+}
+
+// envValue returns the environment variables as a map of strings.
+func envValue() cty.Value {
+	vals := map[string]cty.Value{}
+
+	for _, kv := range os.Environ() {
+		if k, v, ok := strings.Cut(kv, "="); ok && k != "" {
+			vals[k] = cty.StringVal(v)
+		}
+	}
+
+	if len(vals) == 0 {
+		return cty.MapValEmpty(cty.String)
+	}
+
+	return cty.MapVal(vals)
+}
+
+// workspace returns the selected workspace of the given dir,
+// which is specified by the TF_WORKSPACE environment variable,
+// or recorded in the environment file of the data dir, defaults to "default".
+func workspace(dir string) string {
+	if ws := os.Getenv("TF_WORKSPACE"); ws != "" {
+		return ws
+	}
+
+	dataDir := os.Getenv("TF_DATA_DIR")
+	if dataDir == "" {
+		dataDir = ".terraform"
+	}
+
+	if !filepath.IsAbs(dataDir) {
+		dataDir = filepath.Join(dir, dataDir)
+	}
+
+	bs, err := os.ReadFile(filepath.Join(dataDir, "environment"))
+	if err == nil {
+		if ws := strings.TrimSpace(string(bs)); ws != "" {
+			return ws
+		}
+	}
+
+	return "default"
+}
+
+// buildVariables returns the values of the given variable blocks,
+// which are assigned by the given vars, the EnvVarPrefix environment variables or the defaults in order.
+func buildVariables(blocks hcl.Blocks, vars map[string]string) (map[string]cty.Value, hcl.Diagnostics) {
+	var (
+		vals  = make(map[string]cty.Value, len(blocks))
+		diags hcl.Diagnostics
+	)
+
+	for _, b := range blocks {
+		name := b.Labels[0]
+
+		if !hclsyntax.ValidIdentifier(name) {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid variable name",
+				Detail:   fmt.Sprintf("The variable name %q is not a valid identifier.", name),
+				Subject:  pointer.Ref(b.LabelRanges[0]),
+			})
+
+			continue
+		}
+
+		if _, exist := vals[name]; exist {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Duplicate variable declaration",
+				Detail:   fmt.Sprintf("A tap variable named %q was already declared.", name),
+				Subject:  pointer.Ref(b.DefRange),
+			})
+
+			continue
+		}
+
+		bc, dDiags := b.Body.Content(&hcl.BodySchema{
+			Attributes: []hcl.AttributeSchema{
+				{
+					Name: "type",
+				},
+				{
+					Name: "default",
+				},
+				{
+					Name: "description",
+				},
+			},
+		})
+		if dDiags.HasErrors() {
+			diags = diags.Extend(dDiags)
+			continue
+		}
+
+		ty := cty.DynamicPseudoType
+
+		if attr, exist := bc.Attributes["type"]; exist {
+			ty, dDiags = typeexpr.TypeConstraint(attr.Expr)
+			if dDiags.HasErrors() {
+				diags = diags.Extend(dDiags)
+				continue
+			}
+		}
+
+		var (
+			val cty.Value
+			src string
+		)
+
+		switch raw, assigned := lookupVariable(name, vars); {
+		case assigned:
+			src = "assigned value"
+			val, dDiags = parseVariableValue(raw, fmt.Sprintf("<value for var.%s>", name), ty)
+		case bc.Attributes["default"] != nil:
+			src = "default value"
+			val, dDiags = bc.Attributes["default"].Expr.Value(nil)
+		default:
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "No value for required variable",
+				Detail: fmt.Sprintf("The tap variable %q is required, "+
+					"assign it by the -tap-var option or the %s%s environment variable.", name, EnvVarPrefix, name),
+				Subject: pointer.Ref(b.DefRange),
+			})
+
+			continue
+		}
+
+		if dDiags.HasErrors() {
+			diags = diags.Extend(dDiags)
+			continue
+		}
+
+		cv, err := convert.Convert(val, ty)
+		if err != nil {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid value for variable",
+				Detail:   fmt.Sprintf("The %s of tap variable %q is not suitable: %v.", src, name, err),
+				Subject:  pointer.Ref(b.DefRange),
+			})
+
+			continue
+		}
+
+		vals[name] = cv
+	}
+
+	// Reject the assigned values of the undeclared variables,
+	// which are usually typos.
+	for _, name := range sortedKeys(vars) {
+		if _, exist := vals[name]; exist || declared(blocks, name) {
+			continue
+		}
+
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Value for undeclared variable",
+			Detail:   fmt.Sprintf("A value is assigned to the tap variable %q, which is not declared.", name),
+		})
+	}
+
+	return vals, diags
+}
+
+// lookupVariable returns the raw value of the given variable name,
+// from the given vars or the EnvVarPrefix environment variables.
+func lookupVariable(name string, vars map[string]string) (string, bool) {
+	if raw, exist := vars[name]; exist {
+		return raw, true
+	}
+
+	return os.LookupEnv(EnvVarPrefix + name)
+}
+
+// parseVariableValue parses the given raw value of a variable in the given type,
+// the value of a primitive or unspecified type is taken literally,
+// otherwise it is parsed as an HCL expression, e.g. ["a", "b"] or {a = "b"}.
+func parseVariableValue(raw, filename string, ty cty.Type) (cty.Value, hcl.Diagnostics) {
+	if ty.IsPrimitiveType() || ty == cty.DynamicPseudoType {
+		return cty.StringVal(raw), nil
+	}
+
+	expr, diags := hclsyntax.ParseExpression([]byte(raw), filename, hcl.InitialPos)
+	if diags.HasErrors() {
+		return cty.NilVal, diags
+	}
+
+	return expr.Value(nil)
+}
+
+// declared returns true if the given variable name is declared by the given blocks.
+func declared(blocks hcl.Blocks, name string) bool {
+	for _, b := range blocks {
+		if b.Labels[0] == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// buildLocals evaluates the given locals blocks into the given hcl.EvalContext,
+// which can refer to the tap variables, the functions and each other.
+func buildLocals(blocks hcl.Blocks, ctx *hcl.EvalContext) hcl.Diagnostics {
+	var (
+		attrs = map[string]*hcl.Attribute{}
+		diags hcl.Diagnostics
+	)
+
+	for _, b := range blocks {
+		as, aDiags := b.Body.JustAttributes()
+		if aDiags.HasErrors() {
+			diags = diags.Extend(aDiags)
+			continue
+		}
+
+		for n, attr := range as {
+			if prev, exist := attrs[n]; exist {
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Duplicate local value definition",
+					Detail:   fmt.Sprintf("A tap local value named %q was already defined at %s.", n, prev.NameRange),
+					Subject:  pointer.Ref(attr.NameRange),
+				})
+
+				continue
+			}
+
+			attrs[n] = attr
+		}
+	}
+
+	vals := make(map[string]cty.Value, len(attrs))
+
+	// Evaluate the locals whose dependencies are evaluated,
+	// until all the locals are evaluated or no progress is made.
+	for len(attrs) != 0 {
+		var evaluated []string
+
+		for _, n := range sortedKeys(attrs) {
+			if dependsOn(attrs[n].Expr, attrs) {
+				continue
+			}
+
+			v, vDiags := attrs[n].Expr.Value(ctx)
+			diags = diags.Extend(vDiags)
+
+			if vDiags.HasErrors() {
+				v = cty.DynamicVal
+			}
+
+			vals[n] = v
+			evaluated = append(evaluated, n)
+		}
+
+		if len(evaluated) == 0 {
+			for _, n := range sortedKeys(attrs) {
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Cycle in local values",
+					Detail:   fmt.Sprintf("The tap local value %q refers to itself through other local values.", n),
+					Subject:  pointer.Ref(attrs[n].NameRange),
+				})
+			}
+
+			break
+		}
+
+		for _, n := range evaluated {
+			delete(attrs, n)
+		}
+
+		ctx.Variables["local"] = cty.ObjectVal(maps.Clone(vals))
+	}
+
+	return diags
+}
+
+// dependsOn returns true if the given expression refers to any of the given pending locals.
+func dependsOn(expr hcl.Expression, pending map[string]*hcl.Attribute) bool {
+	for _, tr := range expr.Variables() {
+		if tr.RootName() != "local" || len(tr) < 2 {
+			continue
+		}
+
+		if ta, ok := tr[1].(hcl.TraverseAttr); ok && pending[ta.Name] != nil {
+			return true
+		}
+	}
+
+	return false
+}
@@ -3,6 +3,9 @@ package tap
 import (
 	"fmt"
 	"os"
+	"path"
+	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/hashicorp/hcl/v2"
@@ -10,27 +13,51 @@ import (
 	"github.com/hashicorp/hcl/v2/hclparse"
 	"github.com/spf13/afero"
 
+	"github.com/seal-io/tap/pkg/terraform"
 	"github.com/seal-io/tap/utils/pointer"
 )
 
 type (
 	Config struct {
-		PathSyntax string
-		Patches    []Patch
+		PathSyntax   string
+		OutputSyntax string // Select from "hcl" or "json".
+		Provenance   bool   // Annotate the rewritten attributes and blocks with the operations.
+		Strict       bool   // Fail on the unmatched patches and operations instead of warning.
+		Patches      []Patch
 	}
 
 	Patch struct {
+		DeclRange       hcl.Range
 		ContinueOnError bool
-		ResourceMode    string // Select from "resource" or "data".
+		Atomic          bool   // Roll back the operations on an object if any of them fails.
+		Priority        int    // Patches are applied in ascending priority, the later one wins.
+		Override        bool   // Acknowledge overwriting the paths written by the previous patches.
+		ResourceMode    string // Select from "resource", "data", "variable", "output" or "locals".
 		ResourceTypes   []string
-		ResourceNames   []string
+		ResourceNames   []string // Glob patterns if ResourceMode is "variable" or "output".
+		ExpectMatches   MatchCount
 		Operations      []Operation
+
+		// ctx is the evaluation context of the tap configuration,
+		// typeAlias, nameMatch and enabled are the expressions referring to tap.resource,
+		// which are evaluated against each object.
+		ctx                           *hcl.EvalContext
+		typeAlias, nameMatch, enabled hcl.Expression
 	}
 
 	Operation struct {
-		Mode  string // Select from "add", "remove", "replace", or "set".
-		Path  string
-		Value Value
+		DeclRange hcl.Range
+		Mode      string // Select from "add", "remove", "replace", or "set".
+		Path      string
+		Value     Value
+		ValueMode string // Select from "expression" or "static".
+
+		// enabled is the expression referring to tap.resource,
+		// which is evaluated against each object,
+		// blockValue is the block form of the value decoded from a value_file,
+		// which is used if the operation targets a block.
+		enabled    hcl.Expression
+		blockValue *hcl.Block
 	}
 
 	Value struct {
@@ -39,68 +66,85 @@ type (
 	}
 )
 
-// HasConfig checks if the given directory has a tap configuration.
-func HasConfig(dir string) (bool, error) {
-	fs := afero.Afero{Fs: afero.NewBasePathFs(afero.NewOsFs(), dir)}
+// LoadOptions holds the options of HasConfig and Load.
+type LoadOptions struct {
+	// Flavor is the flavor of the delegated CLI, defaults to terraform.FlavorTerraform.
+	Flavor terraform.Flavor
+	// Variables holds the raw values of the tap variables,
+	// which take precedence over the EnvVarPrefix environment variables.
+	Variables map[string]string
+}
 
-	{
-		gs, err := afero.Glob(fs, "*_tap.hcl")
-		if err != nil {
-			return false, fmt.Errorf("failed to glob files suffix with `_tap.hcl`: %w", err)
-		}
+// LoadOption configures the LoadOptions.
+type LoadOption func(*LoadOptions)
 
-		if len(gs) > 0 {
-			return true, nil
-		}
+// WithFlavor configures the flavor of the delegated CLI,
+// with terraform.FlavorOpenTofu, the tap.tofu and *_tap.tofu files are loaded as well,
+// and take precedence over the tap.hcl and *_tap.hcl files with the same name.
+func WithFlavor(f terraform.Flavor) LoadOption {
+	return func(o *LoadOptions) {
+		o.Flavor = f
 	}
+}
 
-	{
-		si, err := fs.Stat("tap.hcl")
-		if err != nil && !os.IsNotExist(err) {
-			return false, fmt.Errorf("failed to stat file `tap.hcl`: %w", err)
-		}
+// WithVariables configures the raw values of the tap variables, e.g. from the -tap-var options,
+// the value of a primitive type variable is taken literally, otherwise it is parsed as an HCL expression.
+func WithVariables(vars map[string]string) LoadOption {
+	return func(o *LoadOptions) {
+		o.Variables = vars
+	}
+}
 
-		if si != nil && !si.IsDir() {
-			return true, nil
-		}
+// newLoadOptions returns the LoadOptions configured by the given LoadOption list.
+func newLoadOptions(opts []LoadOption) LoadOptions {
+	o := LoadOptions{
+		Flavor: terraform.FlavorTerraform,
 	}
 
-	return false, nil
+	for i := range opts {
+		opts[i](&o)
+	}
+
+	return o
 }
 
-// Load loads the tap configuration from the given directory,
-// returns nil if no tap configuration is found.
-func Load(dir string) (*Config, error) {
+// HasConfig checks if the given directory has a tap configuration.
+func HasConfig(dir string, opts ...LoadOption) (bool, error) {
 	fs := afero.Afero{Fs: afero.NewBasePathFs(afero.NewOsFs(), dir)}
 
-	var files []os.FileInfo
-	{
-		gs, err := afero.Glob(fs, "*_tap.hcl")
-		if err != nil {
-			return nil, fmt.Errorf("failed to glob files suffix with `_tap.hcl`: %w", err)
-		}
+	files, err := configFiles(fs, opts...)
+	if err != nil {
+		return false, err
+	}
 
-		files = make([]os.FileInfo, 0, len(gs))
+	return len(files) > 0, nil
+}
 
-		for i := range gs {
-			si, err := fs.Stat(gs[i])
-			if err != nil && !os.IsNotExist(err) {
-				return nil, fmt.Errorf("failed to stat file `%s`: %w", gs[i], err)
-			}
+// ConfigFiles returns the names of the tap configuration files of the given directory in loading order.
+func ConfigFiles(dir string, opts ...LoadOption) ([]string, error) {
+	fs := afero.Afero{Fs: afero.NewBasePathFs(afero.NewOsFs(), dir)}
 
-			if si != nil && !si.IsDir() {
-				files = append(files, si)
-			}
-		}
+	files, err := configFiles(fs, opts...)
+	if err != nil {
+		return nil, err
+	}
 
-		si, err := fs.Stat("tap.hcl")
-		if err != nil && !os.IsNotExist(err) {
-			return nil, fmt.Errorf("failed to stat file `tap.hcl`: %w", err)
-		}
+	names := make([]string, 0, len(files))
+	for i := range files {
+		names = append(names, files[i].Name())
+	}
 
-		if si != nil && !si.IsDir() {
-			files = append(files, si)
-		}
+	return names, nil
+}
+
+// Load loads the tap configuration from the given directory,
+// returns nil if no tap configuration is found.
+func Load(dir string, opts ...LoadOption) (*Config, error) {
+	fs := afero.Afero{Fs: afero.NewBasePathFs(afero.NewOsFs(), dir)}
+
+	files, err := configFiles(fs, opts...)
+	if err != nil {
+		return nil, err
 	}
 
 	if len(files) == 0 {
@@ -128,7 +172,7 @@ func Load(dir string) (*Config, error) {
 		}
 	}
 
-	cfg, diags := buildConfig(hcl.MergeFiles(bodies))
+	cfg, diags := buildConfig(hcl.MergeFiles(bodies), dir, newLoadOptions(opts))
 	if diags.HasErrors() {
 		return nil, fmt.Errorf("failed to build tap config: %w", diags)
 	}
@@ -136,7 +180,68 @@ func Load(dir string) (*Config, error) {
 	return cfg, nil
 }
 
-func buildConfig(body hcl.Body) (*Config, hcl.Diagnostics) {
+// configFiles returns the tap configuration files of the given filesystem,
+// the *_tap.hcl files come first in name order, then the tap.hcl file.
+//
+// With terraform.FlavorOpenTofu, a .tofu file replaces the .hcl file with the same name.
+func configFiles(fs afero.Afero, opts ...LoadOption) ([]os.FileInfo, error) {
+	o := newLoadOptions(opts)
+
+	exts := []string{".hcl"}
+	if o.Flavor == terraform.FlavorOpenTofu {
+		exts = append(exts, ".tofu")
+	}
+
+	var (
+		names []string
+		found = map[string]os.FileInfo{}
+	)
+
+	for _, ext := range exts {
+		gs, err := afero.Glob(fs, "*_tap"+ext)
+		if err != nil {
+			return nil, fmt.Errorf("failed to glob files suffix with `_tap%s`: %w", ext, err)
+		}
+
+		gs = append(gs, "tap"+ext)
+
+		for i := range gs {
+			si, err := fs.Stat(gs[i])
+			if err != nil && !os.IsNotExist(err) {
+				return nil, fmt.Errorf("failed to stat file `%s`: %w", gs[i], err)
+			}
+
+			if si == nil || si.IsDir() {
+				continue
+			}
+
+			n := strings.TrimSuffix(gs[i], ext)
+			if _, exist := found[n]; !exist {
+				names = append(names, n)
+			}
+
+			found[n] = si
+		}
+	}
+
+	// Sort by name, but keep the tap.* file at last.
+	sort.Slice(names, func(i, j int) bool {
+		if names[i] == "tap" || names[j] == "tap" {
+			return names[j] == "tap" && names[i] != "tap"
+		}
+
+		return names[i] < names[j]
+	})
+
+	files := make([]os.FileInfo, 0, len(names))
+	for _, n := range names {
+		files = append(files, found[n])
+	}
+
+	return files, nil
+}
+
+func buildConfig(body hcl.Body, dir string, o LoadOptions) (*Config, hcl.Diagnostics) {
 	bc, remain, diags := body.PartialContent(&hcl.BodySchema{
 		Blocks: []hcl.BlockHeaderSchema{
 			{
@@ -148,18 +253,30 @@ func buildConfig(body hcl.Body) (*Config, hcl.Diagnostics) {
 		return nil, diags
 	}
 
+	// Ignore the configuration without the tap block.
 	b := bc.Blocks.OfType("tap")
-	if b == nil {
+	if len(b) == 0 {
 		return nil, diags
 	}
 
 	var v struct {
 		ContinueOnError bool     `hcl:"continue_on_error,optional"`
+		Atomic          *bool    `hcl:"atomic,optional"`
 		PathSyntax      string   `hcl:"path_syntax,optional"`
+		OutputSyntax    string   `hcl:"output_syntax,optional"`
+		Provenance      bool     `hcl:"provenance,optional"`
+		Strict          bool     `hcl:"strict,optional"`
 		Remain          hcl.Body `hcl:",remain"`
 	}
 
-	diags = gohcl.DecodeBody(b[0].Body, nil, &v)
+	// Evaluate the tap variables and locals,
+	// which can be referred by the rest of the tap configuration.
+	ctx, tb, diags := buildEvalContext(b[0].Body, dir, o.Variables)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	diags = gohcl.DecodeBody(tb, ctx, &v)
 	if diags.HasErrors() {
 		return nil, diags
 	}
@@ -168,15 +285,31 @@ func buildConfig(body hcl.Body) (*Config, hcl.Diagnostics) {
 		v.PathSyntax = "json_pointer"
 	}
 
+	switch v.OutputSyntax {
+	case "":
+		v.OutputSyntax = "hcl"
+	case "hcl", "json":
+	default:
+		return nil, diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid output syntax",
+			Detail:   fmt.Sprintf("The output syntax %q is not supported, select from \"hcl\" or \"json\".", v.OutputSyntax),
+			Subject:  pointer.Ref(b[0].DefRange),
+		})
+	}
+
 	cfg := Config{
-		PathSyntax: v.PathSyntax,
+		PathSyntax:   v.PathSyntax,
+		OutputSyntax: v.OutputSyntax,
+		Provenance:   v.Provenance,
+		Strict:       v.Strict,
 	}
-	diags = buildResourcePatches(remain, &cfg, v.ContinueOnError)
+	diags = buildPatches(remain, ctx, dir, &cfg, v.ContinueOnError, pointer.BoolDeref(v.Atomic, true))
 
 	return &cfg, diags
 }
 
-func buildResourcePatches(remain hcl.Body, cfg *Config, coe bool) hcl.Diagnostics {
+func buildPatches(remain hcl.Body, ctx *hcl.EvalContext, dir string, cfg *Config, coe, atomic bool) hcl.Diagnostics {
 	bc, diags := remain.Content(&hcl.BodySchema{
 		Blocks: []hcl.BlockHeaderSchema{
 			{
@@ -187,6 +320,17 @@ func buildResourcePatches(remain hcl.Body, cfg *Config, coe bool) hcl.Diagnostic
 				Type:       "data",
 				LabelNames: []string{"type"},
 			},
+			{
+				Type:       "variable",
+				LabelNames: []string{"name"},
+			},
+			{
+				Type:       "output",
+				LabelNames: []string{"name"},
+			},
+			{
+				Type: "locals",
+			},
 		},
 	})
 	if diags.HasErrors() {
@@ -200,49 +344,202 @@ func buildResourcePatches(remain hcl.Body, cfg *Config, coe bool) hcl.Diagnostic
 	for i := range bc.Blocks {
 		b := bc.Blocks[i]
 
-		var v struct {
-			ContinueOnError *bool    `hcl:"continue_on_error,optional"`
-			TypeAlias       []string `hcl:"type_alias,optional"`
-			NameMatch       []string `hcl:"name_match,optional"`
-			Remain          hcl.Body `hcl:",remain"`
+		var (
+			rp      Patch
+			enabled bool
+			dDiags  hcl.Diagnostics
+		)
+
+		switch b.Type {
+		case "resource", "data":
+			rp, enabled, dDiags = buildResourcePatch(b, ctx, dir, coe, atomic)
+		case "variable", "output":
+			rp, enabled, dDiags = buildNamedValuePatch(b, ctx, dir, coe, atomic)
+		case "locals":
+			rp, enabled, dDiags = buildLocalsPatch(b, ctx, dir, coe, atomic)
 		}
 
-		dDiags := gohcl.DecodeBody(b.Body, nil, &v)
 		if dDiags.HasErrors() {
 			diags = diags.Extend(dDiags)
 			continue
 		}
 
-		rp := Patch{
-			ContinueOnError: pointer.BoolDeref(v.ContinueOnError, coe),
-			ResourceMode:    b.Type,
-			ResourceTypes:   append([]string{b.Labels[0]}, v.TypeAlias...),
-			ResourceNames:   v.NameMatch,
-		}
-
-		dDiags = buildOperations(v.Remain, &rp)
-		if dDiags.HasErrors() {
-			diags = diags.Extend(dDiags)
+		// Drop the disabled patch,
+		// or the patch whose operations are all disabled.
+		if !enabled || len(rp.Operations) == 0 {
 			continue
 		}
 
-		if len(rp.Operations) == 0 {
-			diags = diags.Append(&hcl.Diagnostic{
+		cfg.Patches = append(cfg.Patches, rp)
+	}
+
+	// Sort by priority,
+	// the patches with the same priority keep the loading order of files and the declaration order.
+	sort.SliceStable(cfg.Patches, func(i, j int) bool {
+		return cfg.Patches[i].Priority < cfg.Patches[j].Priority
+	})
+
+	return diags
+}
+
+// buildResourcePatch returns the patch of the given block,
+// and whether the patch is enabled, the disabled patch is not built.
+func buildResourcePatch(b *hcl.Block, ctx *hcl.EvalContext, dir string, coe, atomic bool) (Patch, bool, hcl.Diagnostics) {
+	var v struct {
+		Enabled         hcl.Expression `hcl:"enabled,optional"`
+		ContinueOnError *bool          `hcl:"continue_on_error,optional"`
+		Atomic          *bool          `hcl:"atomic,optional"`
+		Priority        int            `hcl:"priority,optional"`
+		Override        bool           `hcl:"override,optional"`
+		TypeAlias       hcl.Expression `hcl:"type_alias,optional"`
+		NameMatch       hcl.Expression `hcl:"name_match,optional"`
+		ExpectMatches   hcl.Expression `hcl:"expect_matches,optional"`
+		Remain          hcl.Body       `hcl:",remain"`
+	}
+
+	diags := gohcl.DecodeBody(b.Body, ctx, &v)
+	if diags.HasErrors() {
+		return Patch{}, false, diags
+	}
+
+	enabled, deferred, diags := buildEnabled(v.Enabled, ctx)
+	if diags.HasErrors() || !enabled {
+		return Patch{}, false, diags
+	}
+
+	rp := Patch{
+		DeclRange:       b.DefRange,
+		ContinueOnError: pointer.BoolDeref(v.ContinueOnError, coe),
+		Atomic:          pointer.BoolDeref(v.Atomic, atomic),
+		Priority:        v.Priority,
+		Override:        v.Override,
+		ResourceMode:    b.Type,
+		ResourceTypes:   []string{b.Labels[0]},
+		ctx:             ctx,
+		enabled:         deferred,
+	}
+
+	var typeAlias []string
+
+	rp.typeAlias, diags = decodeDeferrable(v.TypeAlias, ctx, &typeAlias)
+	if diags.HasErrors() {
+		return Patch{}, false, diags
+	}
+
+	rp.ResourceTypes = append(rp.ResourceTypes, typeAlias...)
+
+	rp.nameMatch, diags = decodeDeferrable(v.NameMatch, ctx, &rp.ResourceNames)
+	if diags.HasErrors() {
+		return Patch{}, false, diags
+	}
+
+	rp.ExpectMatches, diags = buildMatchCount(v.ExpectMatches, ctx)
+	if diags.HasErrors() {
+		return Patch{}, false, diags
+	}
+
+	diags = buildOperations(b, v.Remain, ctx, dir, &rp)
+
+	return rp, true, diags
+}
+
+// buildNamedValuePatch returns the patch of the given block,
+// and whether the patch is enabled, the disabled patch is not built.
+func buildNamedValuePatch(b *hcl.Block, ctx *hcl.EvalContext, dir string, coe, atomic bool) (Patch, bool, hcl.Diagnostics) {
+	var v struct {
+		Enabled         hcl.Expression `hcl:"enabled,optional"`
+		ContinueOnError *bool          `hcl:"continue_on_error,optional"`
+		Atomic          *bool          `hcl:"atomic,optional"`
+		Priority        int            `hcl:"priority,optional"`
+		Override        bool           `hcl:"override,optional"`
+		ExpectMatches   hcl.Expression `hcl:"expect_matches,optional"`
+		Remain          hcl.Body       `hcl:",remain"`
+	}
+
+	diags := gohcl.DecodeBody(b.Body, ctx, &v)
+	if diags.HasErrors() {
+		return Patch{}, false, diags
+	}
+
+	enabled, deferred, diags := buildEnabled(v.Enabled, ctx)
+	if diags.HasErrors() || !enabled {
+		return Patch{}, false, diags
+	}
+
+	if _, err := path.Match(b.Labels[0], ""); err != nil {
+		return Patch{}, false, hcl.Diagnostics{
+			{
 				Severity: hcl.DiagError,
-				Summary: fmt.Sprintf(
-					"Patch %q block requires at least one Operation block",
-					strings.Join([]string{rp.ResourceMode, rp.ResourceTypes[0]}, " ")),
-				Subject: pointer.Ref(v.Remain.MissingItemRange()),
-			})
+				Summary:  fmt.Sprintf("Invalid name pattern of patch %q block", b.Type),
+				Detail:   fmt.Sprintf("The name pattern %q is not a valid glob pattern: %v.", b.Labels[0], err),
+				Subject:  pointer.Ref(b.LabelRanges[0]),
+			},
 		}
+	}
 
-		cfg.Patches = append(cfg.Patches, rp)
+	rp := Patch{
+		DeclRange:       b.DefRange,
+		ContinueOnError: pointer.BoolDeref(v.ContinueOnError, coe),
+		Atomic:          pointer.BoolDeref(v.Atomic, atomic),
+		Priority:        v.Priority,
+		Override:        v.Override,
+		ResourceMode:    b.Type,
+		ResourceNames:   []string{b.Labels[0]},
+		ctx:             ctx,
+		enabled:         deferred,
 	}
 
-	return diags
+	rp.ExpectMatches, diags = buildMatchCount(v.ExpectMatches, ctx)
+	if diags.HasErrors() {
+		return Patch{}, false, diags
+	}
+
+	diags = buildOperations(b, v.Remain, ctx, dir, &rp)
+
+	return rp, true, diags
+}
+
+// buildLocalsPatch returns the patch of the given block,
+// and whether the patch is enabled, the disabled patch is not built.
+func buildLocalsPatch(b *hcl.Block, ctx *hcl.EvalContext, dir string, coe, atomic bool) (Patch, bool, hcl.Diagnostics) {
+	var v struct {
+		Enabled         hcl.Expression `hcl:"enabled,optional"`
+		ContinueOnError *bool          `hcl:"continue_on_error,optional"`
+		Atomic          *bool          `hcl:"atomic,optional"`
+		Priority        int            `hcl:"priority,optional"`
+		Override        bool           `hcl:"override,optional"`
+		Remain          hcl.Body       `hcl:",remain"`
+	}
+
+	diags := gohcl.DecodeBody(b.Body, ctx, &v)
+	if diags.HasErrors() {
+		return Patch{}, false, diags
+	}
+
+	enabled, deferred, diags := buildEnabled(v.Enabled, ctx)
+	if diags.HasErrors() || !enabled {
+		return Patch{}, false, diags
+	}
+
+	rp := Patch{
+		DeclRange:       b.DefRange,
+		ContinueOnError: pointer.BoolDeref(v.ContinueOnError, coe),
+		Atomic:          pointer.BoolDeref(v.Atomic, atomic),
+		Priority:        v.Priority,
+		Override:        v.Override,
+		ResourceMode:    b.Type,
+		ctx:             ctx,
+		enabled:         deferred,
+	}
+
+	diags = buildOperations(b, v.Remain, ctx, dir, &rp)
+
+	return rp, true, diags
 }
 
-func buildOperations(remain hcl.Body, rp *Patch) hcl.Diagnostics {
+// buildOperations builds the operations of the given patch block into the given patch,
+// the disabled operations are not built.
+func buildOperations(pb *hcl.Block, remain hcl.Body, ctx *hcl.EvalContext, dir string, rp *Patch) hcl.Diagnostics {
 	bc, diags := remain.Content(&hcl.BodySchema{
 		Blocks: []hcl.BlockHeaderSchema{
 			// Basic mode.
@@ -265,6 +562,16 @@ func buildOperations(remain hcl.Body, rp *Patch) hcl.Diagnostics {
 		return diags
 	}
 
+	if len(bc.Blocks) == 0 {
+		return diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary: fmt.Sprintf(
+				"Patch %q block requires at least one Operation block",
+				strings.Join(append([]string{pb.Type}, pb.Labels...), " ")),
+			Subject: pointer.Ref(pb.Body.MissingItemRange()),
+		})
+	}
+
 	if rp.Operations == nil {
 		rp.Operations = make([]Operation, 0, len(bc.Blocks))
 	}
@@ -273,23 +580,54 @@ func buildOperations(remain hcl.Body, rp *Patch) hcl.Diagnostics {
 		b := bc.Blocks[i]
 
 		var v struct {
-			Path   string   `hcl:"path"`
-			Remain hcl.Body `hcl:",remain"`
+			Enabled   hcl.Expression `hcl:"enabled,optional"`
+			Path      string         `hcl:"path"`
+			ValueMode string         `hcl:"value_mode,optional"`
+			Remain    hcl.Body       `hcl:",remain"`
 		}
 
-		dDiags := gohcl.DecodeBody(b.Body, nil, &v)
+		dDiags := gohcl.DecodeBody(b.Body, ctx, &v)
 		if dDiags.HasErrors() {
 			diags = diags.Extend(dDiags)
 			continue
 		}
 
+		enabled, deferred, eDiags := buildEnabled(v.Enabled, ctx)
+		if eDiags.HasErrors() {
+			diags = diags.Extend(eDiags)
+			continue
+		}
+
+		if !enabled {
+			continue
+		}
+
+		switch v.ValueMode {
+		case "":
+			v.ValueMode = "expression"
+		case "expression", "static":
+		default:
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid value mode",
+				Detail: fmt.Sprintf("The value mode %q is not supported, select from \"expression\" or \"static\".",
+					v.ValueMode),
+				Subject: pointer.Ref(b.DefRange),
+			})
+
+			continue
+		}
+
 		op := Operation{
-			Mode: b.Type,
-			Path: v.Path,
+			DeclRange: b.DefRange,
+			Mode:      b.Type,
+			Path:      v.Path,
+			ValueMode: v.ValueMode,
+			enabled:   deferred,
 		}
 
 		if b.Type != "remove" {
-			dDiags = buildValue(v.Remain, &op)
+			dDiags = buildValue(v.Remain, ctx, dir, &op)
 			if dDiags.HasErrors() {
 				diags = diags.Extend(dDiags)
 				continue
@@ -302,12 +640,38 @@ func buildOperations(remain hcl.Body, rp *Patch) hcl.Diagnostics {
 	return diags
 }
 
-func buildValue(remain hcl.Body, op *Operation) hcl.Diagnostics {
+// buildEnabled evaluates the given enabled expression with the given context, defaults to true,
+// the expression referring to tap.resource is returned to evaluate against each object instead.
+func buildEnabled(expr hcl.Expression, ctx *hcl.EvalContext) (bool, hcl.Expression, hcl.Diagnostics) {
+	var enabled *bool
+
+	deferred, diags := decodeDeferrable(expr, ctx, &enabled)
+	if diags.HasErrors() {
+		return false, nil, diags
+	}
+
+	return pointer.BoolDeref(enabled, true), deferred, nil
+}
+
+// decodeDeferrable decodes the given expression into the given target with the given context,
+// unless the expression refers to tap.resource, which is returned to evaluate against each object instead.
+func decodeDeferrable(expr hcl.Expression, ctx *hcl.EvalContext, target any) (hcl.Expression, hcl.Diagnostics) {
+	if refersToTap(expr) {
+		return expr, nil
+	}
+
+	return nil, gohcl.DecodeExpression(expr, ctx, target)
+}
+
+func buildValue(remain hcl.Body, ctx *hcl.EvalContext, dir string, op *Operation) hcl.Diagnostics {
 	bc, diags := remain.Content(&hcl.BodySchema{
 		Attributes: []hcl.AttributeSchema{
 			{
 				Name: "value",
 			},
+			{
+				Name: "value_file",
+			},
 		},
 		Blocks: []hcl.BlockHeaderSchema{
 			{
@@ -319,10 +683,28 @@ func buildValue(remain hcl.Body, op *Operation) hcl.Diagnostics {
 		return diags
 	}
 
-	if attr, exist := bc.Attributes["value"]; exist {
+	attr, blks := bc.Attributes["value"], bc.Blocks.OfType("value")
+
+	if fattr, exist := bc.Attributes["value_file"]; exist {
+		if attr != nil || len(blks) != 0 {
+			return hcl.Diagnostics{
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Conflicting value",
+					Detail: fmt.Sprintf("Operation %q block accepts either a value or a value_file, but not both.",
+						op.Mode),
+					Subject: pointer.Ref(fattr.NameRange),
+				},
+			}
+		}
+
+		return buildFileValue(fattr, ctx, dir, op)
+	}
+
+	if attr != nil {
 		op.Value.Attribute = attr
 		return nil
-	} else if blks := bc.Blocks.OfType("value"); len(blks) == 1 {
+	} else if len(blks) == 1 {
 		op.Value.Block = blks[0]
 		return nil
 	}
@@ -331,9 +713,58 @@ func buildValue(remain hcl.Body, op *Operation) hcl.Diagnostics {
 		{
 			Severity: hcl.DiagError,
 			Summary: fmt.Sprintf(
-				"Operation %q block requires either a value attribute or a value block",
+				"Operation %q block requires either a value attribute, a value block or a value_file attribute",
 				op.Mode),
 			Subject: &bc.MissingItemRange,
 		},
 	}
 }
+
+// buildFileValue decodes the JSON or YAML file of the given value_file attribute into the value of the given operation,
+// the file path is relative to the given dir.
+//
+// The value is written as an attribute, or as a block if the operation targets a block and the value is an object.
+func buildFileValue(attr *hcl.Attribute, ctx *hcl.EvalContext, dir string, op *Operation) hcl.Diagnostics {
+	var fn string
+
+	diags := gohcl.DecodeExpression(attr.Expr, ctx, &fn)
+	if diags.HasErrors() {
+		return diags
+	}
+
+	if !filepath.IsAbs(fn) {
+		fn = filepath.Join(dir, fn)
+	}
+
+	v, err := decodeValueFile(fn)
+	if err != nil {
+		return hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid value file",
+				Detail:   fmt.Sprintf("The value file cannot be decoded: %v.", err),
+				Subject:  attr.Expr.Range().Ptr(),
+			},
+		}
+	}
+
+	rng := attr.Expr.Range()
+
+	op.Value.Attribute = &hcl.Attribute{
+		Name:      "value",
+		Expr:      exprForValue(v, rng),
+		Range:     attr.Range,
+		NameRange: attr.NameRange,
+	}
+
+	if ty := v.Type(); !v.IsNull() && (ty.IsObjectType() || ty.IsMapType()) {
+		op.blockValue = &hcl.Block{
+			Type:      "value",
+			Body:      bodyForValue(v, rng),
+			DefRange:  attr.NameRange,
+			TypeRange: attr.NameRange,
+		}
+	}
+
+	return nil
+}
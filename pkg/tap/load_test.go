@@ -6,7 +6,10 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/hashicorp/hcl/v2"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/seal-io/tap/pkg/terraform"
 )
 
 func TestLoad(t *testing.T) {
@@ -22,11 +25,17 @@ func TestLoad(t *testing.T) {
 			continue
 		}
 
-		cfg, err := Load(filepath.Join(testCasesDataDir, tc.Name()))
+		var opts []LoadOption
+		if strings.HasPrefix(tc.Name(), "tofu_") {
+			opts = append(opts, WithFlavor(terraform.FlavorOpenTofu))
+		}
+
+		cfg, err := Load(filepath.Join(testCasesDataDir, tc.Name()), opts...)
 
 		switch n := tc.Name(); {
-		case n == "none":
+		case n == "none", n == "no_tap_block":
 			assert.NoError(t, err, n)
+			assert.Nil(t, cfg, n)
 		case strings.HasPrefix(n, "invalid_"):
 			assert.Error(t, err, n)
 		default:
@@ -36,3 +45,123 @@ func TestLoad(t *testing.T) {
 		}
 	}
 }
+
+func TestLoad_openTofu(t *testing.T) {
+	dir := filepath.Join("testdata", "load", "tofu_with_tofu_files")
+
+	// Without OpenTofu, the .tofu files are not recognized.
+	_, err := Load(dir)
+	assert.Error(t, err, "invalid tap.hcl should be loaded without OpenTofu")
+
+	// With OpenTofu, tap.tofu takes precedence over tap.hcl.
+	cfg, err := Load(dir, WithFlavor(terraform.FlavorOpenTofu))
+	if assert.NoError(t, err) && assert.NotNil(t, cfg) {
+		assert.Len(t, cfg.Patches, 1)
+		assert.Equal(t, "hcl", cfg.OutputSyntax)
+	}
+
+	has, err := HasConfig(filepath.Join("testdata", "load", "none"), WithFlavor(terraform.FlavorOpenTofu))
+	if assert.NoError(t, err) {
+		assert.False(t, has)
+	}
+}
+
+func TestLoad_variables(t *testing.T) {
+	dir := filepath.Join("testdata", "load", "variables")
+
+	// With the defaults.
+	cfg, err := Load(dir)
+	if assert.NoError(t, err) && assert.Len(t, cfg.Patches, 1) {
+		p := cfg.Patches[0]
+		assert.True(t, p.ContinueOnError)
+		assert.Equal(t, []string{"nginx"}, p.ResourceNames)
+		assert.Equal(t, "== 1", p.ExpectMatches.String())
+
+		if assert.Len(t, p.Operations, 2) {
+			assert.Equal(t, "/metadata/0/labels/environment", p.Operations[0].Path)
+			assert.Equal(t, "/spec/0/replicas", p.Operations[1].Path)
+		}
+	}
+
+	// Required variables and cyclic locals.
+	_, err = Load(filepath.Join("testdata", "load", "invalid_variables"))
+
+	var diags hcl.Diagnostics
+	if assert.ErrorAs(t, err, &diags) {
+		var summaries []string
+		for _, d := range diags {
+			summaries = append(summaries, d.Summary)
+		}
+
+		assert.Equal(t, []string{
+			"No value for required variable",
+			"Cycle in local values",
+			"Cycle in local values",
+		}, summaries)
+	}
+
+	// The environment variables override the defaults,
+	// and the given variables override the environment variables.
+	t.Setenv(EnvVarPrefix+"environment", "production")
+	t.Setenv(EnvVarPrefix+"apps", `["Nginx", "Redis"]`)
+
+	cfg, err = Load(dir, WithVariables(map[string]string{"apps": `["Nginx"]`}))
+	if assert.NoError(t, err) && assert.Len(t, cfg.Patches, 1) {
+		p := cfg.Patches[0]
+		assert.False(t, p.ContinueOnError)
+		assert.Equal(t, []string{"nginx"}, p.ResourceNames)
+	}
+
+	// Invalid values and undeclared variables.
+	_, err = Load(dir, WithVariables(map[string]string{"replicas": "three"}))
+	assert.ErrorContains(t, err, "Invalid value for variable")
+
+	_, err = Load(dir, WithVariables(map[string]string{"replica": "3"}))
+	assert.ErrorContains(t, err, "Value for undeclared variable")
+}
+
+func TestLoad_enabled(t *testing.T) {
+	dir := filepath.Join("testdata", "load", "enabled")
+
+	t.Setenv("CI", "")
+	t.Setenv("TF_WORKSPACE", "")
+	t.Setenv("TF_DATA_DIR", "")
+
+	countOperations := func(cfg *Config) []int {
+		r := make([]int, 0, len(cfg.Patches))
+		for i := range cfg.Patches {
+			r = append(r, len(cfg.Patches[i].Operations))
+		}
+
+		return r
+	}
+
+	// In the default workspace.
+	cfg, err := Load(dir)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []int{1}, countOperations(cfg))
+	}
+
+	// In the workspace recorded in the data dir.
+	t.Setenv("TF_DATA_DIR", "data")
+
+	cfg, err = Load(dir)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []int{1, 1}, countOperations(cfg))
+	}
+
+	// In the workspace specified by TF_WORKSPACE,
+	// and with the CI environment variable.
+	t.Setenv("TF_WORKSPACE", "staging")
+	t.Setenv("CI", "true")
+
+	cfg, err = Load(dir, WithVariables(map[string]string{"environment": "dev"}))
+	if assert.NoError(t, err) {
+		assert.Equal(t, []int{2}, countOperations(cfg))
+	}
+
+	cfg, err = Load(dir, WithVariables(map[string]string{"environment": "staging"}))
+	if assert.NoError(t, err) {
+		assert.Empty(t, cfg.Patches)
+	}
+}
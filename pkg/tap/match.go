@@ -0,0 +1,142 @@
+package tap
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/seal-io/tap/utils/pointer"
+)
+
+type (
+	// MatchCount constrains the number of objects matched by a patch,
+	// all the constraints must be satisfied.
+	MatchCount []MatchConstraint
+
+	MatchConstraint struct {
+		Operator string // Select from "==", "!=", ">", ">=", "<" or "<=".
+		Count    int
+	}
+)
+
+// Satisfied returns true if the given number satisfies all the constraints.
+func (mc MatchCount) Satisfied(n int) bool {
+	for _, c := range mc {
+		var ok bool
+
+		switch c.Operator {
+		case "==":
+			ok = n == c.Count
+		case "!=":
+			ok = n != c.Count
+		case ">":
+			ok = n > c.Count
+		case ">=":
+			ok = n >= c.Count
+		case "<":
+			ok = n < c.Count
+		case "<=":
+			ok = n <= c.Count
+		}
+
+		if !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (mc MatchCount) String() string {
+	ss := make([]string, len(mc))
+	for i := range mc {
+		ss[i] = mc[i].Operator + " " + strconv.Itoa(mc[i].Count)
+	}
+
+	return strings.Join(ss, ", ")
+}
+
+// buildMatchCount decodes the given expect_matches expression,
+// which is either an exact count, or a string of comma-separated constraints like ">= 1, < 3".
+func buildMatchCount(expr hcl.Expression, ctx *hcl.EvalContext) (MatchCount, hcl.Diagnostics) {
+	if expr == nil {
+		return nil, nil
+	}
+
+	v, diags := expr.Value(ctx)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	if v.IsNull() {
+		return nil, nil
+	}
+
+	invalid := func(detail string) hcl.Diagnostics {
+		return hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid expect_matches",
+				Detail:   detail,
+				Subject:  pointer.Ref(expr.Range()),
+			},
+		}
+	}
+
+	switch v.Type() {
+	case cty.Number:
+		bf := v.AsBigFloat()
+
+		n, acc := bf.Int64()
+		if !bf.IsInt() || acc != 0 || n < 0 {
+			return nil, invalid("The expected number of matches must be a non-negative integer.")
+		}
+
+		return MatchCount{{Operator: "==", Count: int(n)}}, nil
+	case cty.String:
+		var mc MatchCount
+
+		for _, s := range strings.Split(v.AsString(), ",") {
+			c, err := parseMatchConstraint(strings.TrimSpace(s))
+			if err != nil {
+				return nil, invalid(fmt.Sprintf("The constraint %q is invalid: %v.", s, err))
+			}
+
+			mc = append(mc, c)
+		}
+
+		return mc, nil
+	}
+
+	return nil, invalid(`The expect_matches must be a number, or a string like ">= 1".`)
+}
+
+// parseMatchConstraint parses the given constraint like ">= 1" or "2".
+func parseMatchConstraint(s string) (MatchConstraint, error) {
+	c := MatchConstraint{Operator: "=="}
+
+	for _, op := range []string{"==", "!=", ">=", "<=", ">", "<", "="} {
+		if strings.HasPrefix(s, op) {
+			c.Operator = op
+			s = strings.TrimSpace(strings.TrimPrefix(s, op))
+
+			break
+		}
+	}
+
+	if c.Operator == "=" {
+		c.Operator = "=="
+	}
+
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return c, fmt.Errorf("%q is not a non-negative integer", s)
+	}
+
+	c.Count = n
+
+	return c, nil
+}
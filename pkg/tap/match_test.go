@@ -0,0 +1,57 @@
+package tap
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildMatchCount(t *testing.T) {
+	testCases := []struct {
+		given    string
+		expected string
+		matches  []int
+		excludes []int
+		invalid  bool
+	}{
+		{given: `2`, expected: "== 2", matches: []int{2}, excludes: []int{0, 1, 3}},
+		{given: `"3"`, expected: "== 3", matches: []int{3}, excludes: []int{2}},
+		{given: `">= 1"`, expected: ">= 1", matches: []int{1, 5}, excludes: []int{0}},
+		{given: `">= 1, < 3"`, expected: ">= 1, < 3", matches: []int{1, 2}, excludes: []int{0, 3}},
+		{given: `"!= 0"`, expected: "!= 0", matches: []int{1}, excludes: []int{0}},
+		{given: `null`},
+		{given: `-1`, invalid: true},
+		{given: `1.5`, invalid: true},
+		{given: `"about 3"`, invalid: true},
+		{given: `true`, invalid: true},
+	}
+
+	for _, tc := range testCases {
+		expr, diags := hclsyntax.ParseExpression([]byte(tc.given), "", hcl.InitialPos)
+		if diags.HasErrors() {
+			t.Fatalf("failed to parse %s: %v", tc.given, diags)
+		}
+
+		mc, diags := buildMatchCount(expr, nil)
+		if tc.invalid {
+			assert.True(t, diags.HasErrors(), tc.given)
+			continue
+		}
+
+		if !assert.False(t, diags.HasErrors(), tc.given) {
+			continue
+		}
+
+		assert.Equal(t, tc.expected, mc.String(), tc.given)
+
+		for _, n := range tc.matches {
+			assert.True(t, mc.Satisfied(n), "%s should be satisfied by %d", tc.given, n)
+		}
+
+		for _, n := range tc.excludes {
+			assert.False(t, mc.Satisfied(n), "%s should not be satisfied by %d", tc.given, n)
+		}
+	}
+}
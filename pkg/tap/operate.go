@@ -6,62 +6,160 @@ import (
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/hashicorp/terraform/configs"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/seal-io/tap/utils/pointer"
 )
 
 type (
 	TerraformResources = map[string]*configs.Resource
+	TerraformBodies    = map[string]hcl.Body
 )
 
-// Operate operates the patch on the given Terraform resources.
-func Operate(tfRess TerraformResources, patch *Patch, pathSyntax string) (TerraformResources, error) {
+// Operate operates the patch on the given Terraform bodies,
+// which are indexed by the address of the owner block.
+func Operate(tfBodies TerraformBodies, patch *Patch, pathSyntax string) error {
+	return operate(tfBodies, nil, patch, pathSyntax, nil)
+}
+
+// operate is the same as Operate, but records the outcome of each operation into the given report,
+// the given objects are exposed as tap.resource to the operations on the bodies of the same address.
+//
+// If the patch is atomic, the operations on each body run as a transaction,
+// the body is changed only if all the operations succeed,
+// otherwise the applied operations are rolled back.
+func operate(tfBodies TerraformBodies, objects map[string]cty.Value, patch *Patch, pathSyntax string, report *Report) error {
 	if patch == nil {
-		return tfRess, nil
+		return nil
 	}
 
-	for _, op := range patch.Operations {
-		po, err := getPathOperator(op.Path, pathSyntax)
+	pos := make([]PathOperator, len(patch.Operations))
+
+	for i := range patch.Operations {
+		po, err := getPathOperator(patch.Operations[i].Path, pathSyntax)
 		if err != nil {
-			return nil, fmt.Errorf("error getting path operator: %w", err)
+			return fmt.Errorf("error getting path operator: %w", err)
+		}
+
+		pos[i] = po
+	}
+
+	// Operate in the address order,
+	// so that the outcomes and errors are deterministic.
+	for _, bn := range sortedKeys(tfBodies) {
+		b := tfBodies[bn]
+
+		// Operate on a copy of the body if atomic.
+		sb, atomic := b.(*hclsyntax.Body)
+		atomic = atomic && patch.Atomic
+
+		tb := b
+		if atomic {
+			tb = copyBody(sb)
 		}
 
-		for rn, r := range tfRess {
-			var nr *configs.Resource
+		var (
+			ctx    = resourceContext(patch.ctx, objects[bn])
+			failed bool
+		)
+
+		for i := range patch.Operations {
+			op, po := &patch.Operations[i], pos[i]
+
+			enabled, diags := evalEnabled(op.enabled, ctx)
+			if diags.HasErrors() {
+				return fmt.Errorf("error evaluating %s on %s: %w", op.Mode, bn, diags)
+			}
+
+			if !enabled {
+				continue
+			}
+
+			// Fold the references to tap.resource,
+			// or all the subexpressions can be evaluated statically.
+			fold := foldsTap
+			if op.ValueMode == "static" {
+				fold = foldsStatic(ctx)
+			}
+
+			value := op.Value
+			if op.blockValue != nil && targetsBlock(po, tb) {
+				value = Value{Block: op.blockValue}
+			}
+
+			if valueFolds(value, fold) {
+				value, diags = foldValue(value, ctx, fold)
+				if diags.HasErrors() {
+					return fmt.Errorf("error evaluating %s on %s: %w", op.Mode, bn, diags)
+				}
+			}
+
+			// Check whether the path has been written by the previous patches.
+			if c := report.conflict(bn, patch, op); c != nil && !patch.Override {
+				return conflictDiagnostics(bn, op, c)
+			}
+
+			var err error
 
 			switch op.Mode {
 			default:
-				return nil, fmt.Errorf("unknown operation mode: %s", op.Mode)
+				return fmt.Errorf("unknown operation mode: %s", op.Mode)
 			case "add":
-				nr, err = po.Add(r, op.Value)
+				err = po.Add(tb, value)
 			case "replace":
-				nr, err = po.Replace(r, op.Value)
+				err = po.Replace(tb, value)
 			case "remove":
-				nr, err = po.Remove(r)
+				err = po.Remove(tb)
 			case "set":
-				nr, err = po.Set(r, op.Value)
+				err = po.Set(tb, value)
 			}
 
-			if err != nil && !patch.ContinueOnError {
-				return nil, fmt.Errorf("error %s on resource %s: %w", op.Mode, rn, err)
-			}
+			report.record(bn, patch, op, pathHead(po), err)
+
+			if err != nil {
+				if !patch.ContinueOnError {
+					return fmt.Errorf("error %s on %s: %w", op.Mode, bn, err)
+				}
 
-			if nr != nil {
-				tfRess[rn] = nr
+				failed = true
 			}
 		}
+
+		switch {
+		case !atomic:
+		case failed:
+			report.rollback(bn, patch)
+		default:
+			*sb = *toHCLSyntaxBody(tb)
+		}
 	}
 
-	return tfRess, nil
+	return nil
+}
+
+// conflictDiagnostics returns the error of the given operation overlapping the given previous operation.
+func conflictDiagnostics(addr string, op *Operation, prev *ReportOperation) hcl.Diagnostics {
+	return hcl.Diagnostics{
+		{
+			Severity: hcl.DiagError,
+			Summary:  "Conflicting patches",
+			Detail: fmt.Sprintf("The %s operation of path %s on %s overlaps the %s operation of path %s at %s, "+
+				"set override = true in the patch to acknowledge the overwrite.",
+				op.Mode, op.Path, addr, prev.Mode, prev.Path, prev.declRange),
+			Subject: pointer.Ref(op.DeclRange),
+		},
+	}
 }
 
 type PathOperator interface {
-	// Add adds Value at the path if not found in the given configs.Resource.
-	Add(*configs.Resource, Value) (*configs.Resource, error)
-	// Replace replaces the value at the path if found in the given configs.Resource.
-	Replace(*configs.Resource, Value) (*configs.Resource, error)
-	// Remove removes the value at the path if found in the given configs.Resource.
-	Remove(*configs.Resource) (*configs.Resource, error)
-	// Set sets the value at the path of the given configs.Resource.
-	Set(*configs.Resource, Value) (*configs.Resource, error)
+	// Add adds Value at the path if not found in the given hcl.Body.
+	Add(hcl.Body, Value) error
+	// Replace replaces the value at the path if found in the given hcl.Body.
+	Replace(hcl.Body, Value) error
+	// Remove removes the value at the path if found in the given hcl.Body.
+	Remove(hcl.Body) error
+	// Set sets the value at the path of the given hcl.Body.
+	Set(hcl.Body, Value) error
 }
 
 func getPathOperator(path, pathSyntax string) (PathOperator, error) {
@@ -73,6 +171,21 @@ func getPathOperator(path, pathSyntax string) (PathOperator, error) {
 	}
 }
 
+// pathHead returns the name of the top-level attribute or block of the given PathOperator.
+func pathHead(po PathOperator) string {
+	if jp, ok := po.(JSONPointerPathOperator); ok && len(jp) != 0 {
+		return jp[0].Value
+	}
+
+	return ""
+}
+
+// targetsBlock returns true if the given PathOperator targets the blocks of the given body.
+func targetsBlock(po PathOperator, body hcl.Body) bool {
+	jp, ok := po.(JSONPointerPathOperator)
+	return ok && jp.targetsBlock(body)
+}
+
 func toHCLSyntaxExpression(expression hcl.Expression) hclsyntax.Expression {
 	return expression.(hclsyntax.Expression)
 }
@@ -8,7 +8,6 @@ import (
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
-	"github.com/hashicorp/terraform/configs"
 )
 
 const (
@@ -41,10 +40,10 @@ func (op JSONPointerPathOperator) String() string {
 	return "/" + strings.Join(ss, "/")
 }
 
-func (op JSONPointerPathOperator) Search(resource *configs.Resource) (target, parent any, err error) {
-	target, ok := resource.Config.(*hclsyntax.Body)
+func (op JSONPointerPathOperator) Search(body hcl.Body) (target, parent any, err error) {
+	target, ok := body.(*hclsyntax.Body)
 	if !ok {
-		return nil, nil, fmt.Errorf("invalid resource type: %T", resource.Config)
+		return nil, nil, fmt.Errorf("invalid body type: %T", body)
 	}
 
 	for i := 0; i < len(op)-1; i++ {
@@ -135,11 +134,40 @@ func (op JSONPointerPathOperator) Search(resource *configs.Resource) (target, pa
 	return target, parent, nil
 }
 
-func (op JSONPointerPathOperator) Add(resource *configs.Resource, value Value) (*configs.Resource, error) {
+// targetsBlock returns true if the path targets the blocks of the given body,
+// which are the existing blocks named by the last segment, or the indexed block.
+func (op JSONPointerPathOperator) targetsBlock(body hcl.Body) bool {
+	target, _, err := op.Search(body)
+	if err != nil {
+		return false
+	}
+
+	seg := op[len(op)-1].Value
+
+	switch t := target.(type) {
+	case []*hclsyntax.Body:
+		return true
+	case *hclsyntax.Body:
+		if _, exist := t.Attributes[seg]; exist {
+			return false
+		}
+
+		for j := range t.Blocks {
+			if t.Blocks[j].Type == seg ||
+				t.Blocks[j].Type == dynamicBlockType && t.Blocks[j].Labels[0] == seg {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (op JSONPointerPathOperator) Add(body hcl.Body, value Value) error {
 	// Search.
-	target, _, err := op.Search(resource)
+	target, _, err := op.Search(body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to search target: %w", err)
+		return fmt.Errorf("failed to search target: %w", err)
 	}
 
 	// Add.
@@ -147,7 +175,7 @@ func (op JSONPointerPathOperator) Add(resource *configs.Resource, value Value) (
 
 	switch t := target.(type) {
 	default:
-		return nil, fmt.Errorf("invalid target type: %s: %T", op, target)
+		return fmt.Errorf("invalid target type: %s: %T", op, target)
 	case *hclsyntax.Body:
 		switch {
 		case value.Attribute != nil:
@@ -156,7 +184,7 @@ func (op JSONPointerPathOperator) Add(resource *configs.Resource, value Value) (
 			}
 
 			if t.Attributes[seg] != nil {
-				return nil, fmt.Errorf("path already exists: %s", op)
+				return fmt.Errorf("path already exists: %s", op)
 			}
 
 			t.Attributes[seg] = &hclsyntax.Attribute{
@@ -172,10 +200,10 @@ func (op JSONPointerPathOperator) Add(resource *configs.Resource, value Value) (
 					continue
 				case dynamicBlockType:
 					if t.Blocks[j].Labels[0] == seg {
-						return nil, fmt.Errorf("path already exists: %s", op)
+						return fmt.Errorf("path already exists: %s", op)
 					}
 				case seg:
-					return nil, fmt.Errorf("path already exists: %s", op)
+					return fmt.Errorf("path already exists: %s", op)
 				}
 			}
 
@@ -189,14 +217,14 @@ func (op JSONPointerPathOperator) Add(resource *configs.Resource, value Value) (
 		}
 	case *hclsyntax.ObjectConsExpr:
 		if value.Attribute == nil {
-			return nil, errors.New("want patch attribute but got patch block")
+			return errors.New("want patch attribute but got patch block")
 		}
 
 		for i := range t.Items {
 			tr, err := hcl.AbsTraversalForExpr(t.Items[i].KeyExpr)
 			if err == nil && !tr.IsRelative() &&
 				tr[0].(hcl.TraverseRoot).Name == seg {
-				return nil, fmt.Errorf("path already exists: %s", op)
+				return fmt.Errorf("path already exists: %s", op)
 			}
 		}
 
@@ -214,27 +242,27 @@ func (op JSONPointerPathOperator) Add(resource *configs.Resource, value Value) (
 		})
 	case *hclsyntax.TupleConsExpr:
 		if value.Attribute == nil {
-			return nil, errors.New("want patch attribute but got patch block")
+			return errors.New("want patch attribute but got patch block")
 		}
 
 		idx, err := strconv.ParseInt(seg, 10, 64)
 		if err != nil {
-			return nil, fmt.Errorf("invalid indexer path: %s", op)
+			return fmt.Errorf("invalid indexer path: %s", op)
 		}
 
 		if idx != -1 {
-			return nil, fmt.Errorf("illegal indexer path: %s", op)
+			return fmt.Errorf("illegal indexer path: %s", op)
 		}
 
 		t.Exprs = append(t.Exprs, toHCLSyntaxExpression(value.Attribute.Expr))
 	case []*hclsyntax.Body:
 		if value.Block == nil {
-			return nil, errors.New("want patch block but got patch attribute")
+			return errors.New("want patch block but got patch attribute")
 		}
 
 		idx, err := strconv.ParseInt(seg, 10, 64)
 		if err != nil {
-			return nil, fmt.Errorf("invalid indexer path: %s", op)
+			return fmt.Errorf("invalid indexer path: %s", op)
 		}
 
 		if idx == -1 {
@@ -242,7 +270,7 @@ func (op JSONPointerPathOperator) Add(resource *configs.Resource, value Value) (
 		}
 
 		if len(t)-1 < int(idx) {
-			return nil, fmt.Errorf("path not found: %s", op)
+			return fmt.Errorf("path not found: %s", op)
 		}
 
 		bd := toHCLSyntaxBody(value.Block.Body)
@@ -253,7 +281,7 @@ func (op JSONPointerPathOperator) Add(resource *configs.Resource, value Value) (
 			}
 
 			if t[idx].Attributes[k] != nil {
-				return nil, fmt.Errorf("attribute %s already exists: %s", k, op)
+				return fmt.Errorf("attribute %s already exists: %s", k, op)
 			}
 
 			t[idx].Attributes[k] = bd.Attributes[k]
@@ -262,14 +290,14 @@ func (op JSONPointerPathOperator) Add(resource *configs.Resource, value Value) (
 		t[idx].Blocks = append(t[idx].Blocks, bd.Blocks...)
 	}
 
-	return resource, nil
+	return nil
 }
 
-func (op JSONPointerPathOperator) Replace(resource *configs.Resource, value Value) (*configs.Resource, error) {
+func (op JSONPointerPathOperator) Replace(body hcl.Body, value Value) error {
 	// Search.
-	target, _, err := op.Search(resource)
+	target, _, err := op.Search(body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to search target: %w", err)
+		return fmt.Errorf("failed to search target: %w", err)
 	}
 
 	// Replace.
@@ -277,12 +305,12 @@ func (op JSONPointerPathOperator) Replace(resource *configs.Resource, value Valu
 
 	switch t := target.(type) {
 	default:
-		return nil, fmt.Errorf("invalid target type: %s: %T", op, target)
+		return fmt.Errorf("invalid target type: %s: %T", op, target)
 	case *hclsyntax.Body:
 		switch {
 		case value.Attribute != nil:
 			if t.Attributes == nil || t.Attributes[seg] == nil {
-				return nil, fmt.Errorf("path not found: %s", op)
+				return fmt.Errorf("path not found: %s", op)
 			}
 
 			t.Attributes[seg].Expr = toHCLSyntaxExpression(value.Attribute.Expr)
@@ -304,7 +332,7 @@ func (op JSONPointerPathOperator) Replace(resource *configs.Resource, value Valu
 			}
 
 			if len(blkIdxes) == 0 {
-				return nil, fmt.Errorf("path not found: %s", op)
+				return fmt.Errorf("path not found: %s", op)
 			}
 
 			for _, j := range blkIdxes {
@@ -318,7 +346,7 @@ func (op JSONPointerPathOperator) Replace(resource *configs.Resource, value Valu
 		}
 	case *hclsyntax.ObjectConsExpr:
 		if value.Attribute == nil {
-			return nil, errors.New("want patch attribute but got patch block")
+			return errors.New("want patch attribute but got patch block")
 		}
 
 		idx := -1
@@ -332,18 +360,18 @@ func (op JSONPointerPathOperator) Replace(resource *configs.Resource, value Valu
 		}
 
 		if idx == -1 {
-			return nil, fmt.Errorf("path not found: %s", op)
+			return fmt.Errorf("path not found: %s", op)
 		}
 
 		t.Items[idx].ValueExpr = toHCLSyntaxExpression(value.Attribute.Expr)
 	case *hclsyntax.TupleConsExpr:
 		if value.Attribute == nil {
-			return nil, errors.New("want patch attribute but got patch block")
+			return errors.New("want patch attribute but got patch block")
 		}
 
 		idx, err := strconv.ParseInt(seg, 10, 64)
 		if err != nil {
-			return nil, fmt.Errorf("invalid indexer path: %s", op)
+			return fmt.Errorf("invalid indexer path: %s", op)
 		}
 
 		if idx == -1 {
@@ -351,18 +379,18 @@ func (op JSONPointerPathOperator) Replace(resource *configs.Resource, value Valu
 		}
 
 		if len(t.Exprs)-1 < int(idx) {
-			return nil, fmt.Errorf("path not found: %s", op)
+			return fmt.Errorf("path not found: %s", op)
 		}
 
 		t.Exprs[idx] = toHCLSyntaxExpression(value.Attribute.Expr)
 	case []*hclsyntax.Body:
 		if value.Block == nil {
-			return nil, errors.New("want patch block but got patch attribute")
+			return errors.New("want patch block but got patch attribute")
 		}
 
 		idx, err := strconv.ParseInt(seg, 10, 64)
 		if err != nil {
-			return nil, fmt.Errorf("invalid indexer path: %s", op)
+			return fmt.Errorf("invalid indexer path: %s", op)
 		}
 
 		if idx == -1 {
@@ -370,7 +398,7 @@ func (op JSONPointerPathOperator) Replace(resource *configs.Resource, value Valu
 		}
 
 		if len(t)-1 < int(idx) {
-			return nil, fmt.Errorf("path not found: %s", op)
+			return fmt.Errorf("path not found: %s", op)
 		}
 
 		bd := toHCLSyntaxBody(value.Block.Body)
@@ -379,14 +407,14 @@ func (op JSONPointerPathOperator) Replace(resource *configs.Resource, value Valu
 		t[idx].Blocks = bd.Blocks
 	}
 
-	return resource, nil
+	return nil
 }
 
-func (op JSONPointerPathOperator) Remove(resource *configs.Resource) (*configs.Resource, error) {
+func (op JSONPointerPathOperator) Remove(body hcl.Body) error {
 	// Search.
-	target, parent, err := op.Search(resource)
+	target, parent, err := op.Search(body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to search target: %w", err)
+		return fmt.Errorf("failed to search target: %w", err)
 	}
 
 	// Remove.
@@ -394,11 +422,11 @@ func (op JSONPointerPathOperator) Remove(resource *configs.Resource) (*configs.R
 
 	switch t := target.(type) {
 	default:
-		return nil, fmt.Errorf("invalid target type: %s: %T", op, target)
+		return fmt.Errorf("invalid target type: %s: %T", op, target)
 	case *hclsyntax.Body:
 		if t.Attributes[seg] != nil {
 			delete(t.Attributes, seg)
-			return resource, nil
+			return nil
 		}
 
 		blks := make([]*hclsyntax.Block, 0, len(t.Blocks))
@@ -428,14 +456,14 @@ func (op JSONPointerPathOperator) Remove(resource *configs.Resource) (*configs.R
 		}
 
 		if idx == len(t.Items) {
-			return nil, fmt.Errorf("path not found: %s", op)
+			return fmt.Errorf("path not found: %s", op)
 		}
 
 		t.Items = append(t.Items[:idx], t.Items[idx+1:]...)
 	case *hclsyntax.TupleConsExpr:
 		idx, err := strconv.ParseInt(seg, 10, 64)
 		if err != nil {
-			return nil, fmt.Errorf("invalid indexer path: %s", op)
+			return fmt.Errorf("invalid indexer path: %s", op)
 		}
 
 		if idx == -1 {
@@ -443,14 +471,14 @@ func (op JSONPointerPathOperator) Remove(resource *configs.Resource) (*configs.R
 		}
 
 		if len(t.Exprs)-1 < int(idx) {
-			return nil, fmt.Errorf("path not found: %s", op)
+			return fmt.Errorf("path not found: %s", op)
 		}
 
 		t.Exprs = append(t.Exprs[:idx], t.Exprs[idx+1:]...)
 	case []*hclsyntax.Body:
 		idx, err := strconv.ParseInt(seg, 10, 64)
 		if err != nil {
-			return nil, fmt.Errorf("invalid indexer path: %s", op)
+			return fmt.Errorf("invalid indexer path: %s", op)
 		}
 
 		if idx == -1 {
@@ -458,7 +486,7 @@ func (op JSONPointerPathOperator) Remove(resource *configs.Resource) (*configs.R
 		}
 
 		if len(t)-1 < int(idx) {
-			return nil, fmt.Errorf("path not found: %s", op)
+			return fmt.Errorf("path not found: %s", op)
 		}
 
 		pSeg := op[len(op)-2].Value
@@ -486,14 +514,14 @@ func (op JSONPointerPathOperator) Remove(resource *configs.Resource) (*configs.R
 		p.Blocks = blks
 	}
 
-	return resource, nil
+	return nil
 }
 
-func (op JSONPointerPathOperator) Set(resource *configs.Resource, value Value) (*configs.Resource, error) {
+func (op JSONPointerPathOperator) Set(body hcl.Body, value Value) error {
 	// Search.
-	target, _, err := op.Search(resource)
+	target, _, err := op.Search(body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to search target: %w", err)
+		return fmt.Errorf("failed to search target: %w", err)
 	}
 
 	// Set.
@@ -501,7 +529,7 @@ func (op JSONPointerPathOperator) Set(resource *configs.Resource, value Value) (
 
 	switch t := target.(type) {
 	default:
-		return nil, fmt.Errorf("invalid target type: %s: %T", op, target)
+		return fmt.Errorf("invalid target type: %s: %T", op, target)
 	case *hclsyntax.Body:
 		switch {
 		case value.Attribute != nil:
@@ -561,7 +589,7 @@ func (op JSONPointerPathOperator) Set(resource *configs.Resource, value Value) (
 		}
 	case *hclsyntax.ObjectConsExpr:
 		if value.Attribute == nil {
-			return nil, errors.New("want patch attribute but got patch block")
+			return errors.New("want patch attribute but got patch block")
 		}
 
 		idx := -1
@@ -594,12 +622,12 @@ func (op JSONPointerPathOperator) Set(resource *configs.Resource, value Value) (
 		t.Items[idx].ValueExpr = toHCLSyntaxExpression(value.Attribute.Expr)
 	case *hclsyntax.TupleConsExpr:
 		if value.Attribute == nil {
-			return nil, errors.New("want patch attribute but got patch block")
+			return errors.New("want patch attribute but got patch block")
 		}
 
 		idx, err := strconv.ParseInt(seg, 10, 64)
 		if err != nil {
-			return nil, fmt.Errorf("invalid indexer path: %s", op)
+			return fmt.Errorf("invalid indexer path: %s", op)
 		}
 
 		if idx == -1 {
@@ -615,12 +643,12 @@ func (op JSONPointerPathOperator) Set(resource *configs.Resource, value Value) (
 		t.Exprs[idx] = toHCLSyntaxExpression(value.Attribute.Expr)
 	case []*hclsyntax.Body:
 		if value.Block == nil {
-			return nil, errors.New("want patch block but got patch attribute")
+			return errors.New("want patch block but got patch attribute")
 		}
 
 		idx, err := strconv.ParseInt(seg, 10, 64)
 		if err != nil {
-			return nil, fmt.Errorf("invalid indexer path: %s", op)
+			return fmt.Errorf("invalid indexer path: %s", op)
 		}
 
 		if idx == -1 {
@@ -628,7 +656,7 @@ func (op JSONPointerPathOperator) Set(resource *configs.Resource, value Value) (
 		}
 
 		if len(t)-1 < int(idx) {
-			return nil, fmt.Errorf("path not found: %s", op)
+			return fmt.Errorf("path not found: %s", op)
 		}
 
 		bd := toHCLSyntaxBody(value.Block.Body)
@@ -637,7 +665,7 @@ func (op JSONPointerPathOperator) Set(resource *configs.Resource, value Value) (
 		t[idx].Blocks = bd.Blocks
 	}
 
-	return resource, nil
+	return nil
 }
 
 func TokenizeJSONPointerPath(path string) []JSONPointerPathToken {
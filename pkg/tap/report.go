@@ -0,0 +1,360 @@
+package tap
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+
+	"github.com/seal-io/tap/pkg/terraform"
+)
+
+// Outcomes of the operations.
+const (
+	OutcomeApplied = "applied"
+	OutcomeSkipped = "skipped" // Failed but skipped by continue_on_error.
+	OutcomeFailed  = "failed"
+
+	OutcomeRolledBack = "rolled_back" // Applied but rolled back by the failure of another operation of the atomic patch.
+)
+
+type (
+	// Report records the outcome of each operation on each selected object.
+	Report struct {
+		Objects  []ReportObject `json:"objects"`
+		Warnings []string       `json:"warnings,omitempty"`
+	}
+
+	ReportObject struct {
+		Address string        `json:"address"` // E.g. "aws_instance.web", "var.name" or "locals".
+		Patches []ReportPatch `json:"patches"`
+	}
+
+	ReportPatch struct {
+		Source     string            `json:"source"` // E.g. "tap.hcl:6".
+		Operations []ReportOperation `json:"operations"`
+	}
+
+	ReportOperation struct {
+		Mode    string `json:"mode"`
+		Path    string `json:"path"`
+		Source  string `json:"source"`
+		Outcome string `json:"outcome"`
+		Error   string `json:"error,omitempty"`
+
+		// head is the name of the top-level attribute or block of the path.
+		head string
+		// declRange is the range of the operation block.
+		declRange hcl.Range
+	}
+)
+
+// record records the outcome of the given operation on the object with the given address.
+func (r *Report) record(addr string, p *Patch, op *Operation, head string, err error) {
+	if r == nil {
+		return
+	}
+
+	ro := ReportOperation{
+		Mode:      op.Mode,
+		Path:      op.Path,
+		Source:    sourceOf(op.DeclRange),
+		Outcome:   OutcomeApplied,
+		head:      head,
+		declRange: op.DeclRange,
+	}
+
+	if err != nil {
+		ro.Outcome = OutcomeFailed
+		if p.ContinueOnError {
+			ro.Outcome = OutcomeSkipped
+		}
+
+		ro.Error = err.Error()
+	}
+
+	var obj *ReportObject
+
+	for i := range r.Objects {
+		if r.Objects[i].Address == addr {
+			obj = &r.Objects[i]
+			break
+		}
+	}
+
+	if obj == nil {
+		r.Objects = append(r.Objects, ReportObject{Address: addr})
+		obj = &r.Objects[len(r.Objects)-1]
+	}
+
+	ps := sourceOf(p.DeclRange)
+	if len(obj.Patches) == 0 || obj.Patches[len(obj.Patches)-1].Source != ps {
+		obj.Patches = append(obj.Patches, ReportPatch{Source: ps})
+	}
+
+	rp := &obj.Patches[len(obj.Patches)-1]
+	rp.Operations = append(rp.Operations, ro)
+}
+
+// rollback marks the applied operations of the given patch on the object with the given address as rolled back,
+// and records a warning if any.
+func (r *Report) rollback(addr string, p *Patch) {
+	if r == nil {
+		return
+	}
+
+	var rolledBack bool
+
+	for i := range r.Objects {
+		if r.Objects[i].Address != addr || len(r.Objects[i].Patches) == 0 {
+			continue
+		}
+
+		rp := &r.Objects[i].Patches[len(r.Objects[i].Patches)-1]
+		for j := range rp.Operations {
+			if rp.Operations[j].Outcome == OutcomeApplied {
+				rp.Operations[j].Outcome = OutcomeRolledBack
+				rolledBack = true
+			}
+		}
+	}
+
+	if !rolledBack {
+		return
+	}
+
+	r.Warnings = append(r.Warnings, fmt.Sprintf("patch at %s is rolled back on %s", sourceOf(p.DeclRange), addr))
+}
+
+// conflict returns the applied operation of the previous patches on the object with the given address,
+// which writes the path overlapping the path of the given operation,
+// or nil if not found.
+//
+// The add operation never conflicts, since it never overwrites the existing value.
+func (r *Report) conflict(addr string, p *Patch, op *Operation) *ReportOperation {
+	if r == nil || op.Mode == "add" {
+		return nil
+	}
+
+	var (
+		ps  = sourceOf(p.DeclRange)
+		tks = TokenizeJSONPointerPath(op.Path)
+	)
+
+	for i := range r.Objects {
+		if r.Objects[i].Address != addr {
+			continue
+		}
+
+		for j := range r.Objects[i].Patches {
+			rp := &r.Objects[i].Patches[j]
+			if rp.Source == ps {
+				continue
+			}
+
+			for k := range rp.Operations {
+				ro := &rp.Operations[k]
+				if ro.Outcome == OutcomeApplied && overlapTokens(TokenizeJSONPointerPath(ro.Path), tks) {
+					return ro
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// applies returns the number of objects applied by each operation of the given patch,
+// the operations rolled back by the atomic patch are counted as applied.
+func (r *Report) applies(p *Patch) []int {
+	var (
+		ps      = sourceOf(p.DeclRange)
+		applies = make([]int, len(p.Operations))
+	)
+
+	for _, obj := range r.Objects {
+		for _, rp := range obj.Patches {
+			if rp.Source != ps {
+				continue
+			}
+
+			for i := range p.Operations {
+				src := sourceOf(p.Operations[i].DeclRange)
+
+				for _, ro := range rp.Operations {
+					if ro.Source == src && (ro.Outcome == OutcomeApplied || ro.Outcome == OutcomeRolledBack) {
+						applies[i]++
+						break
+					}
+				}
+			}
+		}
+	}
+
+	return applies
+}
+
+// check checks the given number of objects selected by the given patch,
+// returns an error if the patch does not match the expected number of objects,
+// records the warnings of the unmatched patch and operations,
+// or returns them as an error if strict.
+func (r *Report) check(p *Patch, objects int, strict bool) error {
+	if p.ExpectMatches != nil {
+		if !p.ExpectMatches.Satisfied(objects) {
+			return fmt.Errorf("patch at %s matches %d object(s), expected %s",
+				sourceOf(p.DeclRange), objects, p.ExpectMatches)
+		}
+	} else if objects == 0 {
+		return r.warn(strict, fmt.Sprintf("patch at %s matches no objects", sourceOf(p.DeclRange)))
+	}
+
+	applies := r.applies(p)
+
+	for i := range p.Operations {
+		if objects == 0 || applies[i] != 0 {
+			continue
+		}
+
+		op := &p.Operations[i]
+
+		err := r.warn(strict, fmt.Sprintf("%s operation at %s applies to no objects", op.Mode, sourceOf(op.DeclRange)))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// warn records the given warning, or returns it as an error if strict.
+func (r *Report) warn(strict bool, msg string) error {
+	if strict {
+		return errors.New(msg)
+	}
+
+	r.Warnings = append(r.Warnings, msg)
+
+	return nil
+}
+
+// sort sorts the objects by address,
+// the patches and operations are kept in the applying order.
+func (r *Report) sort() {
+	sort.SliceStable(r.Objects, func(i, j int) bool {
+		return r.Objects[i].Address < r.Objects[j].Address
+	})
+}
+
+// Annotations returns the comments of the applied operations,
+// which can be written above the rewritten attributes and blocks by terraform.WithAnnotations.
+func (r *Report) Annotations() terraform.Annotations {
+	if r == nil {
+		return nil
+	}
+
+	a := terraform.Annotations{}
+
+	for _, obj := range r.Objects {
+		for _, p := range obj.Patches {
+			for _, op := range p.Operations {
+				if op.Outcome != OutcomeApplied || op.head == "" {
+					continue
+				}
+
+				if a[obj.Address] == nil {
+					a[obj.Address] = map[string][]string{}
+				}
+
+				a[obj.Address][op.head] = append(a[obj.Address][op.head],
+					fmt.Sprintf("tap: %s %s (%s)", op.Mode, op.Path, op.Source))
+			}
+		}
+	}
+
+	return a
+}
+
+// Operations returns the applied operations on the object with the given address,
+// which may change the value at the given path,
+// the path consists of the object keys in string and the array indexes in int.
+func (r *Report) Operations(addr string, path []any) []ReportOperation {
+	if r == nil {
+		return nil
+	}
+
+	var ops []ReportOperation
+
+	for _, obj := range r.Objects {
+		if obj.Address != addr {
+			continue
+		}
+
+		for _, p := range obj.Patches {
+			for _, op := range p.Operations {
+				if op.Outcome == OutcomeApplied && overlapPath(TokenizeJSONPointerPath(op.Path), path) {
+					ops = append(ops, op)
+				}
+			}
+		}
+	}
+
+	return ops
+}
+
+// overlapPath returns true if one of the given paths is the prefix of another,
+// the appending index "-" and the negative indexes match any index.
+func overlapPath(tks []JSONPointerPathToken, path []any) bool {
+	for i := 0; i < len(tks) && i < len(path); i++ {
+		v := tks[i].Value
+		if strings.HasPrefix(v, "-") {
+			if _, ok := path[i].(int); ok {
+				continue
+			}
+		}
+
+		if v != fmt.Sprint(path[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// overlapTokens returns true if one of the given paths is the prefix of another,
+// the negative indexes match any index,
+// while the appending index "-" matches nothing since the appended item is always new.
+func overlapTokens(a, b []JSONPointerPathToken) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		x, y := a[i].Value, b[i].Value
+
+		switch {
+		case x == "-" || y == "-":
+			return false
+		case x == y:
+		case isNegativeIndex(x) && isIndex(y), isIndex(x) && isNegativeIndex(y):
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// isIndex returns true if the given path token is an array index.
+func isIndex(v string) bool {
+	_, err := strconv.Atoi(v)
+	return err == nil
+}
+
+// isNegativeIndex returns true if the given path token is a negative array index.
+func isNegativeIndex(v string) bool {
+	return strings.HasPrefix(v, "-") && isIndex(v)
+}
+
+// sourceOf returns the "file:line" of the given range.
+func sourceOf(r hcl.Range) string {
+	return fmt.Sprintf("%s:%d", r.Filename, r.Start.Line)
+}
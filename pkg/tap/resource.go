@@ -0,0 +1,132 @@
+package tap
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/zclconf/go-cty/cty"
+	"golang.org/x/exp/slices"
+
+	"github.com/seal-io/tap/utils/pointer"
+)
+
+// resourceObjectType is the type of tap.resource,
+// which describes the object matched by a patch.
+var resourceObjectType = cty.Object(map[string]cty.Type{
+	"mode":    cty.String,
+	"type":    cty.String,
+	"name":    cty.String,
+	"address": cty.String,
+	"module":  cty.String,
+	"file":    cty.String,
+})
+
+// resourceObject returns the tap.resource value of the object declared in the root module,
+// the type is empty unless the mode is "resource" or "data".
+func resourceObject(mode, typ, name, addr string, declRange hcl.Range) cty.Value {
+	return cty.ObjectVal(map[string]cty.Value{
+		"mode":    cty.StringVal(mode),
+		"type":    cty.StringVal(typ),
+		"name":    cty.StringVal(name),
+		"address": cty.StringVal(addr),
+		"module":  cty.StringVal(""),
+		"file":    cty.StringVal(declRange.Filename),
+	})
+}
+
+// resourceContext returns the child hcl.EvalContext of the given context,
+// which exposes the given object as tap.resource.
+func resourceContext(ctx *hcl.EvalContext, obj cty.Value) *hcl.EvalContext {
+	if ctx == nil {
+		ctx = &hcl.EvalContext{}
+	}
+
+	if obj == cty.NilVal {
+		obj = cty.NullVal(resourceObjectType)
+	}
+
+	c := ctx.NewChild()
+	c.Variables = map[string]cty.Value{
+		"tap": cty.ObjectVal(map[string]cty.Value{
+			"resource": obj,
+		}),
+	}
+
+	return c
+}
+
+// selects returns true if the given object of the given type and name is selected by the patch,
+// the selectors and enabled condition referring to tap.resource are evaluated against the given object.
+func (p *Patch) selects(typ, name string, obj cty.Value) (bool, hcl.Diagnostics) {
+	var (
+		ctx   = resourceContext(p.ctx, obj)
+		types = p.ResourceTypes
+		names = p.ResourceNames
+	)
+
+	if p.typeAlias != nil {
+		var typeAlias []string
+
+		diags := gohcl.DecodeExpression(p.typeAlias, ctx, &typeAlias)
+		if diags.HasErrors() {
+			return false, diags
+		}
+
+		types = append(slices.Clip(types), typeAlias...)
+	}
+
+	if p.nameMatch != nil {
+		names = nil
+
+		diags := gohcl.DecodeExpression(p.nameMatch, ctx, &names)
+		if diags.HasErrors() {
+			return false, diags
+		}
+	}
+
+	switch p.ResourceMode {
+	case "resource", "data":
+		if !slices.Contains(types, typ) ||
+			len(names) != 0 && !slices.Contains(names, name) {
+			return false, nil
+		}
+	case "variable", "output":
+		if !matchNames(names, name) {
+			return false, nil
+		}
+	}
+
+	return evalEnabled(p.enabled, ctx)
+}
+
+// evalEnabled evaluates the given enabled expression with the given context,
+// returns true if the expression is nil.
+func evalEnabled(expr hcl.Expression, ctx *hcl.EvalContext) (bool, hcl.Diagnostics) {
+	if expr == nil {
+		return true, nil
+	}
+
+	var enabled *bool
+
+	diags := gohcl.DecodeExpression(expr, ctx, &enabled)
+	if diags.HasErrors() {
+		return false, diags
+	}
+
+	return pointer.BoolDeref(enabled, true), nil
+}
+
+// refersToTap returns true if the given expression refers to the tap object,
+// e.g. tap.resource.name.
+func refersToTap(expr hcl.Expression) bool {
+	if expr == nil {
+		return false
+	}
+
+	for _, tr := range expr.Variables() {
+		if tr.RootName() == "tap" {
+			return true
+		}
+	}
+
+	return false
+}
@@ -27,19 +27,32 @@ resource "kubernetes_deployment_v1" "deploy" {
     namespace = local.namespace
     labels = {
       app = local.name
+      # # patch # deploy = "true"
       nested_object = {
         app = local.name
+        # # patch # key = "true"
+        # # patch # array_key = ["x", "y"]
       }
-      nested_array = ["x"]
-      nested_array_object = [{
-        x = "y"
-        }, [{
-          y = "x"
-      }]]
+      nested_array = [
+        "x"
+        # # patch # "y"
+      ]
+      nested_array_object = [
+        {
+          x = "y"
+        },
+        [
+          {
+            y = "x"
+            # # patch # key = "true"
+          }
+        ]
+      ]
       nested_object_object = {
         x = {
           y = {
             z = "x"
+            # # patch # key = "true"
           }
         }
       }
@@ -48,8 +61,13 @@ resource "kubernetes_deployment_v1" "deploy" {
   spec {
     replicas = 1
     selector {
+      # # patch # match_labels = local.selectors
     }
     template {
+      # # patch #
+      # metadata {
+      #   labels = local.selectors
+      # }
       spec {
         container {
           name  = "nginx"
@@ -68,8 +86,10 @@ resource "kubernetes_service_v1" "svc" {
     name      = kubernetes_deployment_v1.deploy.metadata[0].name
     namespace = kubernetes_deployment_v1.deploy.metadata[0].namespace
   }
+
   dynamic "spec" {
     for_each = [{}]
+
     content {
       selector = local.selectors
       type     = "ClusterIP"
@@ -77,7 +97,11 @@ resource "kubernetes_service_v1" "svc" {
         port        = 80
         target_port = 80
       }
+      # # patch #
+      # port {
+      #    port        = 443
+      #    target_port = 443
+      # }
     }
   }
 }
-
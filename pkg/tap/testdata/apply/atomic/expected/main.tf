@@ -0,0 +1,20 @@
+resource "aws_s3_bucket" "logs" {
+  bucket = "logs"
+
+  # tap: replace /versioning/0/enabled (tap.hcl:16)
+  versioning {
+    enabled = true
+  }
+  # tap: set /force_destroy (tap.hcl:26)
+  force_destroy = true
+  # tap: set /tags (tap.hcl:9)
+  tags = {
+    managed-by = "tap"
+  }
+}
+
+resource "aws_s3_bucket" "assets" {
+  bucket = "assets"
+  # tap: set /force_destroy (tap.hcl:26)
+  force_destroy = true
+}
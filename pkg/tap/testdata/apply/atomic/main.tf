@@ -0,0 +1,11 @@
+resource "aws_s3_bucket" "logs" {
+  bucket = "logs"
+
+  versioning {
+    enabled = false
+  }
+}
+
+resource "aws_s3_bucket" "assets" {
+  bucket = "assets"
+}
@@ -0,0 +1,51 @@
+tap {
+  continue_on_error = true
+  provenance        = true
+}
+
+# the assets bucket has no versioning block,
+# so the patch is rolled back on it.
+resource "aws_s3_bucket" {
+  set {
+    path  = "/tags"
+    value = {
+      managed-by = "tap"
+    }
+  }
+
+  replace {
+    path  = "/versioning/0/enabled"
+    value = true
+  }
+}
+
+# the patch is applied partially on the assets bucket.
+resource "aws_s3_bucket" {
+  atomic = false
+
+  set {
+    path  = "/force_destroy"
+    value = true
+  }
+
+  replace {
+    path  = "/versioning/0/mfa_delete"
+    value = true
+  }
+}
+
+# the patch is rolled back on the only matched object,
+# the set operation is not reported as applying to no objects.
+resource "aws_s3_bucket" {
+  name_match = ["assets"]
+
+  set {
+    path  = "/acl"
+    value = "private"
+  }
+
+  replace {
+    path  = "/versioning/0/status"
+    value = "Enabled"
+  }
+}
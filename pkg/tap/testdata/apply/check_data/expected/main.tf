@@ -0,0 +1,24 @@
+resource "aws_instance" "web" {
+  ami           = "ami-a1b2c3d4"
+  instance_type = "t2.micro"
+}
+
+moved {
+  from = aws_instance.app
+  to   = aws_instance.web
+}
+
+check "health_check" {
+  data "http" "web" {
+    url        = "https://${aws_instance.web.public_ip}"
+    depends_on = [aws_instance.web]
+    request_headers = {
+      Accept = "application/json"
+    }
+  }
+
+  assert {
+    condition     = data.http.web.status_code == 200
+    error_message = "${data.http.web.url} returned an unhealthy status code"
+  }
+}
@@ -0,0 +1,17 @@
+tap {
+  path_syntax = "json_pointer"
+}
+
+data "http" {
+  set {
+    path  = "/request_headers"
+    value = {
+      Accept = "application/json"
+    }
+  }
+
+  set {
+    path  = "/depends_on"
+    value = [aws_instance.web]
+  }
+}
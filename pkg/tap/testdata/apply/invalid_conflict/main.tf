@@ -0,0 +1,8 @@
+resource "aws_s3_bucket" "logs" {
+  bucket = "logs"
+  acl    = "log-delivery-write"
+}
+
+resource "aws_s3_bucket" "assets" {
+  bucket = "assets"
+}
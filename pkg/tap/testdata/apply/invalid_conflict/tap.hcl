@@ -0,0 +1,21 @@
+tap {
+}
+
+resource "aws_s3_bucket" {
+  set {
+    path  = "/tags"
+    value = {
+      managed-by = "tap"
+    }
+  }
+}
+
+# overwrites the tags set by the previous patch without override.
+resource "aws_s3_bucket" {
+  name_match = ["assets"]
+
+  set {
+    path  = "/tags/managed-by"
+    value = "platform"
+  }
+}
@@ -0,0 +1,7 @@
+resource "aws_s3_bucket" "logs" {
+  bucket = "logs"
+}
+
+resource "aws_s3_bucket" "assets" {
+  bucket = "assets"
+}
@@ -0,0 +1,27 @@
+tap {
+  path_syntax = "json_pointer"
+}
+
+resource "aws_s3_bucket" {
+  # both buckets are matched.
+  expect_matches = 2
+
+  set {
+    path  = "/force_destroy"
+    value = true
+  }
+}
+
+resource "aws_s3_bucket" {
+  name_match = ["log*"]
+
+  # only one bucket is matched.
+  expect_matches = ">= 2"
+
+  set {
+    path  = "/tags"
+    value = {
+      usage = "logging"
+    }
+  }
+}
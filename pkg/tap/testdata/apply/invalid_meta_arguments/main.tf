@@ -0,0 +1,14 @@
+provider "aws" {
+  region = "us-east-1"
+}
+
+resource "aws_db_instance" "replica" {
+  count = 2
+
+  identifier     = "replica-${count.index}"
+  instance_class = "db.t3.micro"
+}
+
+resource "aws_s3_bucket" "logs" {
+  bucket = "logs"
+}
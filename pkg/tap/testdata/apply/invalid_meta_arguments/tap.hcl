@@ -0,0 +1,20 @@
+tap {
+  continue_on_error = true
+  path_syntax       = "json_pointer"
+}
+
+resource "aws_db_instance" {
+  # count and for_each are mutually-exclusive.
+  set {
+    path  = "/for_each"
+    value = toset(["a", "b"])
+  }
+}
+
+resource "aws_s3_bucket" {
+  # aws.east is not declared.
+  set {
+    path  = "/provider"
+    value = aws.east
+  }
+}
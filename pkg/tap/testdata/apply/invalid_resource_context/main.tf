@@ -0,0 +1,41 @@
+resource "kubernetes_deployment" "nginx" {
+  metadata {
+    name = "nginx"
+
+    labels = {
+      tier = "frontend"
+    }
+  }
+
+  spec {
+    replicas = 1
+  }
+}
+
+resource "kubernetes_deployment" "redis" {
+  metadata {
+    name = "redis"
+
+    labels = {
+      tier = "backend"
+    }
+  }
+
+  spec {
+    replicas = 1
+  }
+}
+
+resource "kubernetes_deployment" "legacy_worker" {
+  metadata {
+    name = "worker"
+  }
+
+  spec {
+    replicas = 1
+  }
+}
+
+variable "region" {
+  type = string
+}
@@ -0,0 +1,10 @@
+tap {}
+
+resource "kubernetes_deployment" {
+  name_match = [tap.resource.labels]
+
+  set {
+    path  = "/metadata/0/labels/app"
+    value = tap.resource.name
+  }
+}
@@ -0,0 +1,12 @@
+tap {
+  path_syntax = "json_pointer"
+  strict      = true
+}
+
+# the typo matches nothing.
+resource "aws_s3_bukcet" {
+  set {
+    path  = "/force_destroy"
+    value = true
+  }
+}
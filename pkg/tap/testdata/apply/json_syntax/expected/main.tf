@@ -0,0 +1,57 @@
+{
+  "variable": {
+    "replicas": {
+      "type": "number",
+      "default": 3,
+      "validation": [
+        {
+          "condition": "${var.replicas > 0}",
+          "error_message": "The replicas must be positive."
+        }
+      ]
+    }
+  },
+  "locals": {
+    "name": "web-${var.replicas}"
+  },
+  "resource": {
+    "kubernetes_deployment_v1": {
+      "deployment": {
+        "metadata": [
+          {
+            "name": "${local.name}",
+            "namespace": "${kubernetes_namespace_v1.ns.metadata[0].name}"
+          }
+        ],
+        "spec": [
+          {
+            "replicas": "${var.replicas}"
+          }
+        ],
+        "lifecycle": [
+          {
+            "create_before_destroy": true,
+            "prevent_destroy": true
+          }
+        ],
+        "depends_on": [
+          "kubernetes_namespace_v1.ns"
+        ]
+      }
+    }
+  }
+}
+
+provider "kubernetes" {
+  config_path = "~/.kube/config"
+}
+
+resource "kubernetes_namespace_v1" "ns" {
+  metadata {
+    name = "default"
+  }
+}
+
+locals {
+  environment = "production"
+}
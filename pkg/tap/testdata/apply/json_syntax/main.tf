@@ -0,0 +1,9 @@
+provider "kubernetes" {
+  config_path = "~/.kube/config"
+}
+
+resource "kubernetes_namespace_v1" "ns" {
+  metadata {
+    name = "default"
+  }
+}
@@ -0,0 +1,51 @@
+tap {
+  path_syntax = "json_pointer"
+}
+
+resource "kubernetes_deployment_v1" {
+  set {
+    path  = "/spec/0/replicas"
+    value = var.replicas
+  }
+
+  set {
+    path  = "/lifecycle/0/prevent_destroy"
+    value = true
+  }
+
+  set {
+    path  = "/depends_on"
+    value = [kubernetes_namespace_v1.ns]
+  }
+}
+
+variable "replicas" {
+  set {
+    path  = "/default"
+    value = 3
+  }
+
+  add {
+    path = "/validation"
+    value {
+      condition     = var.replicas > 0
+      error_message = "The replicas must be positive."
+    }
+  }
+}
+
+locals {
+  set {
+    path  = "/name"
+    value = "web-${var.replicas}"
+  }
+
+  remove {
+    path = "/unused"
+  }
+
+  add {
+    path  = "/environment"
+    value = "production"
+  }
+}
@@ -0,0 +1,45 @@
+terraform {
+  required_version = ">= 1.0"
+
+  required_providers {
+    aws = {
+      source  = "hashicorp/aws"
+      version = ">= 5.0.0"
+    }
+  }
+}
+
+provider "aws" {
+  region = "us-east-1"
+}
+
+provider "aws" {
+  alias  = "west"
+  region = "us-west-2"
+}
+
+resource "aws_db_instance" "primary" {
+  identifier     = "primary"
+  instance_class = "db.t3.micro"
+
+  lifecycle {
+    ignore_changes  = [password, tags]
+    prevent_destroy = true
+  }
+}
+
+resource "aws_db_instance" "replica" {
+  count = 3
+
+  identifier     = "replica-${count.index}"
+  instance_class = "db.t3.micro"
+  depends_on     = [aws_db_instance.primary]
+  lifecycle {
+    prevent_destroy = true
+  }
+}
+
+resource "aws_s3_bucket" "logs" {
+  bucket   = "logs"
+  provider = aws.west
+}
@@ -0,0 +1,45 @@
+tap {
+  path_syntax = "json_pointer"
+}
+
+resource "aws_db_instance" {
+  name_match = ["primary"]
+
+  set {
+    path  = "/lifecycle/0/prevent_destroy"
+    value = true
+  }
+
+  add {
+    path  = "/lifecycle/0/ignore_changes/-1"
+    value = tags
+  }
+}
+
+resource "aws_db_instance" {
+  name_match = ["replica"]
+
+  set {
+    path  = "/count"
+    value = 3
+  }
+
+  set {
+    path  = "/depends_on"
+    value = [aws_db_instance.primary]
+  }
+
+  set {
+    path = "/lifecycle"
+    value {
+      prevent_destroy = true
+    }
+  }
+}
+
+resource "aws_s3_bucket" {
+  set {
+    path  = "/provider"
+    value = aws.west
+  }
+}
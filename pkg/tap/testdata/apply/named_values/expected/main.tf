@@ -0,0 +1,38 @@
+terraform {
+  required_version = ">= 1.0"
+}
+
+variable "db_password" {
+  type = string
+}
+
+variable "replicas" {
+  type    = number
+  default = 3
+  validation {
+    condition     = var.replicas > 0
+    error_message = "The replicas must be positive."
+  }
+}
+
+locals {
+  name      = "nginx"
+  namespace = "production"
+  labels = {
+    app = local.name
+  }
+}
+
+output "db_password" {
+  value     = var.db_password
+  sensitive = true
+}
+
+output "admin_password" {
+  value     = var.db_password
+  sensitive = true
+}
+
+output "name" {
+  value = local.name
+}
@@ -0,0 +1,29 @@
+terraform {
+  required_version = ">= 1.0"
+}
+
+variable "db_password" {
+  type = string
+}
+
+variable "replicas" {
+  type    = number
+  default = 1
+}
+
+locals {
+  name      = "nginx"
+  namespace = "default"
+}
+
+output "db_password" {
+  value = var.db_password
+}
+
+output "admin_password" {
+  value = var.db_password
+}
+
+output "name" {
+  value = local.name
+}
@@ -0,0 +1,39 @@
+tap {
+  path_syntax = "json_pointer"
+}
+
+variable "replicas" {
+  set {
+    path  = "/default"
+    value = 3
+  }
+
+  add {
+    path = "/validation"
+    value {
+      condition     = var.replicas > 0
+      error_message = "The replicas must be positive."
+    }
+  }
+}
+
+output "*_password" {
+  set {
+    path  = "/sensitive"
+    value = true
+  }
+}
+
+locals {
+  set {
+    path  = "/namespace"
+    value = "production"
+  }
+
+  add {
+    path  = "/labels"
+    value = {
+      app = local.name
+    }
+  }
+}
@@ -0,0 +1,12 @@
+# applied at last because of the highest priority,
+# although base_tap.hcl is loaded before tap.hcl.
+resource "aws_s3_bucket" {
+  name_match = ["logs"]
+  priority   = 10
+  override   = true
+
+  set {
+    path  = "/acl"
+    value = "log-delivery-write"
+  }
+}
@@ -0,0 +1,22 @@
+resource "aws_s3_bucket" "logs" {
+  bucket = "logs"
+  acl    = "log-delivery-write"
+  # tap: set /tags (tap.hcl:11)
+  # tap: add /tags/owner (tap.hcl:21)
+  tags = {
+    managed-by = "tap"
+    owner      = "platform"
+  }
+}
+
+resource "aws_s3_bucket" "assets" {
+  bucket = "assets"
+  # tap: set /acl (tap.hcl:6)
+  acl = "private"
+  # tap: set /tags (tap.hcl:11)
+  # tap: add /tags/owner (tap.hcl:21)
+  tags = {
+    managed-by = "tap"
+    owner      = "platform"
+  }
+}
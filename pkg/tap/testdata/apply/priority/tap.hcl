@@ -0,0 +1,25 @@
+tap {
+  provenance = true
+}
+
+resource "aws_s3_bucket" {
+  set {
+    path  = "/acl"
+    value = "private"
+  }
+
+  set {
+    path  = "/tags"
+    value = {
+      managed-by = "tap"
+    }
+  }
+}
+
+# appending never conflicts.
+resource "aws_s3_bucket" {
+  add {
+    path  = "/tags/owner"
+    value = "platform"
+  }
+}
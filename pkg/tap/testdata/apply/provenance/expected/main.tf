@@ -0,0 +1,53 @@
+variable "replicas" {
+  type = number
+  # tap: set /default (tap.hcl:35)
+  default = 3
+}
+
+locals {
+  # the labels of the application.
+  labels = {
+    app = "nginx"
+  }
+  # tap: set /namespace (tap.hcl:49)
+  namespace = "default"
+}
+
+# tap: remove /wait_for_rollout (tap.hcl:11)
+resource "kubernetes_deployment" "nginx" {
+  # tap: set /metadata/0/labels (tap.hcl:6)
+  metadata {
+    name = "nginx"
+    labels = merge(local.labels, {
+      managed-by = "tap"
+    })
+  }
+
+  # tap: add /spec/0/template/0/spec/0/container/0 (tap.hcl:15)
+  spec {
+    replicas = var.replicas
+
+    template {
+      metadata {
+        labels = local.labels
+      }
+
+      spec {
+        container {
+          name  = "nginx"
+          image = "nginx:1.25"
+          port {
+            container_port = 80
+          }
+        }
+      }
+    }
+  }
+
+}
+
+output "name" {
+  value = kubernetes_deployment.nginx.metadata[0].name
+  # tap: add /description (tap.hcl:42)
+  description = "The name of the deployment."
+}
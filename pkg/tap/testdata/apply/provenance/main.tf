@@ -0,0 +1,41 @@
+variable "replicas" {
+  type    = number
+  default = 1
+}
+
+locals {
+  # the labels of the application.
+  labels = {
+    app = "nginx"
+  }
+}
+
+resource "kubernetes_deployment" "nginx" {
+  metadata {
+    name   = "nginx"
+    labels = local.labels
+  }
+
+  spec {
+    replicas = var.replicas
+
+    template {
+      metadata {
+        labels = local.labels
+      }
+
+      spec {
+        container {
+          name  = "nginx"
+          image = "nginx:1.25"
+        }
+      }
+    }
+  }
+
+  wait_for_rollout = true
+}
+
+output "name" {
+  value = kubernetes_deployment.nginx.metadata[0].name
+}
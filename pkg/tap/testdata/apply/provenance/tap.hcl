@@ -0,0 +1,53 @@
+tap {
+  provenance = true
+}
+
+resource "kubernetes_deployment" {
+  set {
+    path  = "/metadata/0/labels"
+    value = merge(local.labels, { managed-by = "tap" })
+  }
+
+  remove {
+    path = "/wait_for_rollout"
+  }
+
+  add {
+    path = "/spec/0/template/0/spec/0/container/0"
+    value {
+      port {
+        container_port = 80
+      }
+    }
+  }
+}
+
+resource "kubernetes_deployment" {
+  continue_on_error = true
+
+  replace {
+    path  = "/spec/0/paused"
+    value = true
+  }
+}
+
+variable "replicas" {
+  set {
+    path  = "/default"
+    value = 3
+  }
+}
+
+output "*" {
+  add {
+    path  = "/description"
+    value = "The name of the deployment."
+  }
+}
+
+locals {
+  set {
+    path  = "/namespace"
+    value = "default"
+  }
+}
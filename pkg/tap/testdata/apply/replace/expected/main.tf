@@ -53,6 +53,7 @@ resource "kubernetes_deployment_v1" "deploy" {
   spec {
     replicas = 1
     selector {
+      # # patch # match_labels = local.selectors
       match_labels = merge(local.selectors, {
         "foo" = "bar"
       })
@@ -72,8 +73,10 @@ resource "kubernetes_service_v1" "svc" {
     name      = kubernetes_deployment_v1.deploy.metadata[0].name
     namespace = kubernetes_deployment_v1.deploy.metadata[0].namespace
   }
+
   dynamic "spec" {
     for_each = [{}]
+
     content {
       selector = local.selectors
       type     = "ClusterIP"
@@ -81,6 +84,11 @@ resource "kubernetes_service_v1" "svc" {
         port        = 8080
         target_port = 8080
       }
+      # # patch #
+      # port {
+      #    port        = 443
+      #    target_port = 443
+      # }
       port {
         port        = 443
         target_port = 443
@@ -88,4 +96,3 @@ resource "kubernetes_service_v1" "svc" {
     }
   }
 }
-
@@ -0,0 +1,51 @@
+resource "kubernetes_deployment" "nginx" {
+  metadata {
+    name = "nginx"
+
+    labels = {
+      tier = "frontend"
+      app  = "nginx"
+    }
+    annotations = {
+      "tap/source" = "main.tf#kubernetes_deployment.nginx"
+    }
+  }
+
+  spec {
+    replicas          = 3
+    min_ready_seconds = 10
+  }
+}
+
+resource "kubernetes_deployment" "redis" {
+  metadata {
+    name = "redis"
+
+    labels = {
+      tier = "backend"
+      app  = "redis"
+    }
+    annotations = {
+      "tap/source" = "main.tf#kubernetes_deployment.redis"
+    }
+  }
+
+  spec {
+    replicas = 1
+  }
+}
+
+resource "kubernetes_deployment" "legacy_worker" {
+  metadata {
+    name = "worker"
+  }
+
+  spec {
+    replicas = 1
+  }
+}
+
+variable "region" {
+  type        = string
+  description = "The region variable."
+}
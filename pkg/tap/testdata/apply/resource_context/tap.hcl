@@ -0,0 +1,42 @@
+tap {}
+
+resource "kubernetes_deployment" {
+  enabled = !startswith(tap.resource.name, "legacy_")
+
+  set {
+    path  = "/metadata/0/labels/app"
+    value = tap.resource.name
+  }
+
+  set {
+    path = "/metadata/0/annotations"
+    value = {
+      "tap/source" = "${tap.resource.file}#${tap.resource.address}"
+    }
+  }
+
+  set {
+    enabled = tap.resource.name == "nginx"
+    path    = "/spec/0/replicas"
+    value   = 3
+  }
+}
+
+variable "*" {
+  set {
+    path  = "/description"
+    value = "The ${tap.resource.name} ${tap.resource.mode}."
+  }
+}
+
+# redis is matched even if the operation is disabled on it.
+resource "kubernetes_deployment" {
+  name_match     = ["nginx", "redis"]
+  expect_matches = 2
+
+  set {
+    enabled = tap.resource.name == "nginx"
+    path    = "/spec/0/min_ready_seconds"
+    value   = 10
+  }
+}
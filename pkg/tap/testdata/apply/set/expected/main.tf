@@ -53,6 +53,7 @@ resource "kubernetes_deployment_v1" "deploy" {
   spec {
     replicas = 1
     selector {
+      # # patch # match_labels = local.selectors
       match_labels = local.selectors
     }
     template {
@@ -68,14 +69,20 @@ resource "kubernetes_service_v1" "svc" {
     name      = kubernetes_deployment_v1.deploy.metadata[0].name
     namespace = kubernetes_deployment_v1.deploy.metadata[0].namespace
   }
+
   dynamic "spec" {
     for_each = [{}]
+
     content {
       port {
         port        = 443
         target_port = 443
       }
+      # # patch #
+      # port {
+      #    port        = 443
+      #    target_port = 443
+      # }
     }
   }
 }
-
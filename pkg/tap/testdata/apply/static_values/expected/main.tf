@@ -0,0 +1,29 @@
+variable "region" {
+  type = string
+}
+
+resource "kubernetes_deployment" "nginx" {
+  metadata {
+    name = "nginx"
+    annotations = {
+      "ci"       = "local"
+      "region"   = "${var.region}-staging"
+      "module"   = path.module
+      "deployed" = timestamp()
+    }
+    labels = {
+      app         = "nginx"
+      cost-center = "cc-42"
+      environment = "staging"
+      owner       = "SRE"
+      team        = "platform"
+    }
+  }
+
+  spec {
+    replicas = 1
+    strategy {
+      type = "Recreate"
+    }
+  }
+}
@@ -0,0 +1,13 @@
+variable "region" {
+  type = string
+}
+
+resource "kubernetes_deployment" "nginx" {
+  metadata {
+    name = "nginx"
+  }
+
+  spec {
+    replicas = 1
+  }
+}
@@ -0,0 +1,41 @@
+tap {
+  variable "environment" {
+    type    = string
+    default = "staging"
+  }
+
+  locals {
+    owner = "sre"
+  }
+}
+
+resource "kubernetes_deployment" {
+  set {
+    value_mode = "static"
+    path       = "/metadata/0/labels"
+    value = merge(jsondecode(file("labels.json")), {
+      environment = var.environment
+      owner       = upper(local.owner)
+      app         = tap.resource.name
+    })
+  }
+
+  set {
+    value_mode = "static"
+    path       = "/metadata/0/annotations"
+    value = {
+      "ci"       = lookup(env, "TAP_TEST_UNSET_VARIABLE", "local")
+      "region"   = "${var.region}-${var.environment}"
+      "module"   = path.module
+      "deployed" = timestamp()
+    }
+  }
+
+  set {
+    value_mode = "static"
+    path       = "/spec/0/strategy"
+    value {
+      type = var.environment == "production" ? "RollingUpdate" : "Recreate"
+    }
+  }
+}
@@ -0,0 +1,32 @@
+resource "kubernetes_deployment" "nginx" {
+  metadata {
+    name = "nginx"
+    labels = {
+      "app.kubernetes.io/managed-by" = "tap"
+      team                           = "platform"
+      tier                           = "frontend"
+    }
+  }
+
+  spec {
+    template {
+      spec {
+        container {
+          name              = "nginx"
+          image             = "nginx:1.25"
+          args              = ["--port", "8080"]
+          image_pull_policy = "Always"
+          port {
+            container_port = 8080
+          }
+          resources {
+            limits = {
+              cpu    = "500m"
+              memory = "256Mi"
+            }
+          }
+        }
+      }
+    }
+  }
+}
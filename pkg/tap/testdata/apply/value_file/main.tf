@@ -0,0 +1,16 @@
+resource "kubernetes_deployment" "nginx" {
+  metadata {
+    name = "nginx"
+  }
+
+  spec {
+    template {
+      spec {
+        container {
+          name  = "nginx"
+          image = "nginx:1.25"
+        }
+      }
+    }
+  }
+}
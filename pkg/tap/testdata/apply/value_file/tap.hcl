@@ -0,0 +1,15 @@
+tap {}
+
+resource "kubernetes_deployment" {
+  # written as an object attribute.
+  set {
+    path       = "/metadata/0/labels"
+    value_file = "patches/labels.yaml"
+  }
+
+  # written as nested blocks, since the path targets a block.
+  add {
+    path       = "/spec/0/template/0/spec/0/container/0"
+    value_file = "patches/container.json"
+  }
+}
@@ -0,0 +1,26 @@
+resource "kubernetes_deployment" "nginx" {
+  metadata {
+    name = "nginx"
+    labels = {
+      app         = "nginx"
+      environment = "dev"
+    }
+  }
+
+  spec {
+    replicas = 3
+  }
+}
+
+resource "kubernetes_deployment" "redis" {
+  metadata {
+    name = "redis"
+    labels = {
+      app = "redis"
+    }
+  }
+
+  spec {
+    replicas = 1
+  }
+}
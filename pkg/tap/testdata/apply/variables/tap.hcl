@@ -0,0 +1,39 @@
+tap {
+  variable "environment" {
+    type        = string
+    default     = "dev"
+    description = "The environment to deploy."
+  }
+
+  variable "apps" {
+    type    = list(string)
+    default = ["nginx"]
+  }
+
+  variable "replicas" {
+    type    = number
+    default = 1
+  }
+
+  locals {
+    label_path = "/metadata/0/labels/${local.label_key}"
+    label_key  = "environment"
+  }
+
+  continue_on_error = var.environment != "production"
+}
+
+resource "kubernetes_deployment" {
+  name_match     = [for app in var.apps : lower(app)]
+  expect_matches = length(var.apps)
+
+  set {
+    path  = local.label_path
+    value = "dev"
+  }
+
+  set {
+    path  = format("/spec/%d/replicas", 0)
+    value = 3
+  }
+}
@@ -1,11 +1,20 @@
 tap {
   continue_on_error = true
+  atomic            = false
   path_syntax       = "tap_pointer"
+  output_syntax     = "json"
+  provenance        = true
+  strict            = true
 }
 
 resource "kubernetes_namespace" {
   type_alias = ["kubernetes_namespace_v1"]
   name_match = null # match all namespaces.
+  atomic     = true
+  priority   = 1
+  override   = true
+
+  expect_matches = ">= 1, < 10"
 
   # always set.
   set {
@@ -100,3 +109,13 @@ data "kubernetes_namespace" {
     value = "test"
   }
 }
+
+resource "kubernetes_deployment" {
+  # evaluate against each deployment.
+  enabled = tap.resource.file == "main.tf"
+
+  set {
+    path  = ".metadata[0].labels.app"
+    value = tap.resource.name
+  }
+}
@@ -0,0 +1,41 @@
+tap {
+  variable "environment" {
+    type    = string
+    default = "dev"
+  }
+}
+
+# production-only hardening.
+resource "aws_db_instance" {
+  enabled = terraform.workspace == "production"
+
+  set {
+    path  = "/deletion_protection"
+    value = true
+  }
+}
+
+# dev-only relaxations.
+resource "aws_db_instance" {
+  enabled = var.environment == "dev"
+
+  set {
+    path  = "/skip_final_snapshot"
+    value = true
+  }
+
+  set {
+    enabled = lookup(env, "CI", "") != ""
+    path    = "/apply_immediately"
+    value   = true
+  }
+}
+
+# all operations are disabled, so the patch is dropped.
+resource "aws_s3_bucket" {
+  set {
+    enabled = false
+    path    = "/force_destroy"
+    value   = true
+  }
+}
@@ -0,0 +1,11 @@
+tap {
+}
+
+resource "kubernetes_deployment" {
+  expect_matches = "about 3"
+
+  set {
+    path  = "/spec/0/replicas"
+    value = 3
+  }
+}
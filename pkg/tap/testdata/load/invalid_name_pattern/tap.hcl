@@ -0,0 +1,10 @@
+tap {
+  path_syntax = "json_pointer"
+}
+
+output "[_password" {
+  set {
+    path  = "/sensitive"
+    value = true
+  }
+}
@@ -0,0 +1,10 @@
+tap {
+  output_syntax = "yaml"
+}
+
+resource "kubernetes_deployment" {
+  set {
+    path  = "/spec/0/replicas"
+    value = 3
+  }
+}
@@ -0,0 +1,9 @@
+tap {}
+
+resource "kubernetes_deployment" {
+  set {
+    path       = "/metadata/0/labels"
+    value      = {}
+    value_file = "labels.yaml"
+  }
+}
@@ -0,0 +1,9 @@
+tap {}
+
+resource "kubernetes_deployment" {
+  set {
+    value_mode = "literal"
+    path       = "/spec/0/replicas"
+    value      = 3
+  }
+}
@@ -0,0 +1,18 @@
+tap {
+  # required but not assigned.
+  variable "environment" {
+    type = string
+  }
+
+  locals {
+    a = local.b
+    b = local.a
+  }
+}
+
+resource "kubernetes_deployment" {
+  set {
+    path  = "/metadata/0/labels/environment"
+    value = "dev"
+  }
+}
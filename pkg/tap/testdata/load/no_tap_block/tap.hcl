@@ -0,0 +1,8 @@
+# the tap configuration without the tap block is ignored.
+
+resource "kubernetes_deployment" {
+  set {
+    path  = "/spec/0/replicas"
+    value = 3
+  }
+}
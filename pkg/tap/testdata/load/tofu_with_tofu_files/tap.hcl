@@ -0,0 +1,5 @@
+# This file is ignored by OpenTofu, since tap.tofu exists.
+
+tap {
+  output_syntax = "yaml"
+}
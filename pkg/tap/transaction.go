@@ -0,0 +1,69 @@
+package tap
+
+import (
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// copyBody returns a deep copy of the given hclsyntax.Body to operate in a transaction,
+// the attributes, blocks, object and tuple expressions are copied,
+// since the operations change them in place,
+// other expressions are shared, since the operations only replace them.
+func copyBody(b *hclsyntax.Body) *hclsyntax.Body {
+	if b == nil {
+		return nil
+	}
+
+	nb := *b
+
+	if b.Attributes != nil {
+		nb.Attributes = make(hclsyntax.Attributes, len(b.Attributes))
+
+		for n, attr := range b.Attributes {
+			na := *attr
+			na.Expr = copyExpr(attr.Expr)
+			nb.Attributes[n] = &na
+		}
+	}
+
+	if b.Blocks != nil {
+		nb.Blocks = make(hclsyntax.Blocks, len(b.Blocks))
+
+		for i, blk := range b.Blocks {
+			nblk := *blk
+			nblk.Body = copyBody(blk.Body)
+			nb.Blocks[i] = &nblk
+		}
+	}
+
+	return &nb
+}
+
+// copyExpr returns a deep copy of the given object or tuple expression,
+// or the given expression itself.
+func copyExpr(expr hclsyntax.Expression) hclsyntax.Expression {
+	switch t := expr.(type) {
+	case *hclsyntax.ObjectConsExpr:
+		nt := *t
+		nt.Items = make([]hclsyntax.ObjectConsItem, len(t.Items))
+
+		for i := range t.Items {
+			nt.Items[i] = hclsyntax.ObjectConsItem{
+				KeyExpr:   t.Items[i].KeyExpr,
+				ValueExpr: copyExpr(t.Items[i].ValueExpr),
+			}
+		}
+
+		return &nt
+	case *hclsyntax.TupleConsExpr:
+		nt := *t
+		nt.Exprs = make([]hclsyntax.Expression, len(t.Exprs))
+
+		for i := range t.Exprs {
+			nt.Exprs[i] = copyExpr(t.Exprs[i])
+		}
+
+		return &nt
+	}
+
+	return expr
+}
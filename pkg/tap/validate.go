@@ -0,0 +1,169 @@
+package tap
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/terraform/configs"
+	"golang.org/x/exp/slices"
+
+	"github.com/seal-io/tap/utils/pointer"
+)
+
+// Validate checks the tap configuration against the Terraform configuration,
+// returns the diagnostics of the patches that match no objects or unexpected number of objects,
+// the operations that fail on the matched objects,
+// and the objects that become invalid after patching.
+//
+// Unlike Apply, Validate goes on after failures to collect all problems,
+// the given Terraform configuration is changed in place.
+func Validate(tfCfg *configs.Config, cfg *Config) hcl.Diagnostics {
+	if tfCfg == nil || cfg == nil {
+		return nil
+	}
+
+	var (
+		diags  hcl.Diagnostics
+		report = &Report{}
+	)
+
+	for i := range cfg.Patches {
+		p := cfg.Patches[i]
+
+		// Apply the patch and continue on error,
+		// so that all the failed operations are recorded,
+		// the matches are checked below,
+		// and the conflicts are detected by the shared report.
+		vp := p
+		vp.ContinueOnError = true
+
+		objects, err := applyPatch(tfCfg.Module, &vp, cfg.PathSyntax, report)
+		if err != nil {
+			var eDiags hcl.Diagnostics
+			if errors.As(err, &eDiags) {
+				diags = diags.Extend(eDiags)
+			} else {
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid patch",
+					Detail:   fmt.Sprintf("The patch cannot be applied: %v.", err),
+					Subject:  pointer.Ref(p.DeclRange),
+				})
+			}
+		}
+
+		switch {
+		case err != nil:
+			// The matches are incomplete if applying stops.
+		case p.ExpectMatches != nil && !p.ExpectMatches.Satisfied(objects):
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Unexpected number of matches",
+				Detail: fmt.Sprintf("The patch matches %d object(s), but expect_matches is %q.",
+					objects, p.ExpectMatches),
+				Subject: pointer.Ref(p.DeclRange),
+			})
+		case p.ExpectMatches == nil && objects == 0:
+			d := unmatchedDiagnostic(tfCfg.Module, &p)
+			if cfg.Strict {
+				d.Severity = hcl.DiagError
+			}
+
+			diags = diags.Append(d)
+		}
+
+		for _, obj := range report.Objects {
+			for _, rp := range obj.Patches {
+				if rp.Source != sourceOf(p.DeclRange) {
+					continue
+				}
+
+				for _, ro := range rp.Operations {
+					if ro.Outcome == OutcomeApplied || ro.Outcome == OutcomeRolledBack {
+						continue
+					}
+
+					diags = diags.Append(operationDiagnostic(obj.Address, &p, ro))
+				}
+			}
+		}
+	}
+
+	return diags
+}
+
+// unmatchedDiagnostic returns the warning of the given patch that matches no objects.
+func unmatchedDiagnostic(m *configs.Module, p *Patch) *hcl.Diagnostic {
+	d := &hcl.Diagnostic{
+		Severity: hcl.DiagWarning,
+		Summary:  "Patch matches no objects",
+		Subject:  pointer.Ref(p.DeclRange),
+	}
+
+	switch p.ResourceMode {
+	case "resource", "data":
+		ress := m.ManagedResources
+		if p.ResourceMode == "data" {
+			ress = m.DataResources
+		}
+
+		known := false
+
+		for _, r := range ress {
+			if slices.Contains(p.ResourceTypes, r.Type) {
+				known = true
+				break
+			}
+		}
+
+		if !known {
+			d.Summary = "Unknown resource type"
+			d.Detail = fmt.Sprintf("No %s block of type %s is declared in the root module.",
+				p.ResourceMode, strings.Join(p.ResourceTypes, " or "))
+
+			return d
+		}
+
+		d.Detail = fmt.Sprintf("No %s block of type %s matches the names %s.",
+			p.ResourceMode, strings.Join(p.ResourceTypes, " or "), strings.Join(p.ResourceNames, ", "))
+	default:
+		d.Detail = fmt.Sprintf("No %s block matches the name pattern %s.",
+			p.ResourceMode, strings.Join(p.ResourceNames, ", "))
+	}
+
+	return d
+}
+
+// operationDiagnostic returns the diagnostic of the given failed operation,
+// which is an error unless the given patch continues on error.
+func operationDiagnostic(addr string, p *Patch, ro ReportOperation) *hcl.Diagnostic {
+	d := &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "Invalid operation",
+		Detail:   fmt.Sprintf("The %s operation on %s failed: %s.", ro.Mode, addr, ro.Error),
+	}
+
+	if p.ContinueOnError {
+		d.Severity = hcl.DiagWarning
+		d.Detail += " The failure is skipped by continue_on_error."
+
+		if p.Atomic {
+			d.Detail = strings.TrimSuffix(d.Detail, ".") + fmt.Sprintf(", and the patch is rolled back on %s.", addr)
+		}
+	}
+
+	if strings.Contains(ro.Error, "path not found") {
+		d.Summary = "Unreachable path"
+	}
+
+	for i := range p.Operations {
+		if sourceOf(p.Operations[i].DeclRange) == ro.Source {
+			d.Subject = pointer.Ref(p.Operations[i].DeclRange)
+			break
+		}
+	}
+
+	return d
+}
@@ -0,0 +1,477 @@
+package tap
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	ctyyaml "github.com/zclconf/go-cty-yaml"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+	"golang.org/x/exp/slices"
+)
+
+// foldFunc returns true if the given subexpression of a value is replaced by its literal value.
+type foldFunc func(hclsyntax.Expression) bool
+
+// foldsTap folds the references to the tap object, e.g. tap.resource.name.
+func foldsTap(e hclsyntax.Expression) bool {
+	st, ok := e.(*hclsyntax.ScopeTraversalExpr)
+	return ok && st.Traversal.RootName() == "tap"
+}
+
+// impureFunctions holds the functions returning different results on each call,
+// which are left to Terraform even if they can be evaluated statically.
+var impureFunctions = []string{"bcrypt", "plantimestamp", "timestamp", "uuid"}
+
+// foldsStatic returns the foldFunc of the given context,
+// which folds the subexpressions referring to the variables and calling the functions of the context only,
+// e.g. var.<name> of the tap variables, local.<name> of the tap locals, env.<name> and file("...").
+//
+// The subexpressions referring to anything else are left as expressions, e.g. var.<name> of the Terraform variables,
+// path.module or aws_instance.web.id, since they are evaluated by Terraform.
+func foldsStatic(ctx *hcl.EvalContext) foldFunc {
+	return func(e hclsyntax.Expression) bool {
+		var (
+			vars   = e.Variables()
+			static = true
+			calls  int
+		)
+
+		hclsyntax.VisitAll(e, func(n hclsyntax.Node) hcl.Diagnostics {
+			if fc, ok := n.(*hclsyntax.FunctionCallExpr); ok {
+				calls++
+				static = static && hasFunction(ctx, fc.Name) && !slices.Contains(impureFunctions, fc.Name)
+			}
+
+			return nil
+		})
+
+		if !static || len(vars) == 0 && calls == 0 {
+			return false
+		}
+
+		for _, tr := range vars {
+			if _, diags := tr.TraverseAbs(ctx); diags.HasErrors() {
+				return false
+			}
+		}
+
+		return true
+	}
+}
+
+// hasFunction returns true if the given function is declared by the given context or its parents.
+func hasFunction(ctx *hcl.EvalContext, name string) bool {
+	for c := ctx; c != nil; c = c.Parent() {
+		if _, exist := c.Functions[name]; exist {
+			return true
+		}
+	}
+
+	return false
+}
+
+// valueFolds returns true if any subexpression of the given Value is folded by the given foldFunc.
+func valueFolds(v Value, fold foldFunc) bool {
+	switch {
+	case v.Attribute != nil:
+		return exprFolds(toHCLSyntaxExpression(v.Attribute.Expr), fold)
+	case v.Block != nil:
+		b, ok := v.Block.Body.(*hclsyntax.Body)
+		return ok && bodyFolds(b, fold)
+	}
+
+	return false
+}
+
+// bodyFolds is the same as valueFolds, but for the attributes of the given body and its nested blocks.
+func bodyFolds(b *hclsyntax.Body, fold foldFunc) bool {
+	for _, attr := range b.Attributes {
+		if exprFolds(attr.Expr, fold) {
+			return true
+		}
+	}
+
+	for _, blk := range b.Blocks {
+		if bodyFolds(blk.Body, fold) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// exprFolds is the same as valueFolds, but for the given expression.
+func exprFolds(expr hclsyntax.Expression, fold foldFunc) bool {
+	folds := false
+
+	hclsyntax.VisitAll(expr, func(n hclsyntax.Node) hcl.Diagnostics {
+		if e, ok := n.(hclsyntax.Expression); ok && !folds {
+			folds = fold(e)
+		}
+
+		return nil
+	})
+
+	return folds
+}
+
+// foldValue returns a copy of the given Value,
+// whose subexpressions accepted by the given foldFunc are replaced by their literal values,
+// which are evaluated with the given context.
+func foldValue(v Value, ctx *hcl.EvalContext, fold foldFunc) (Value, hcl.Diagnostics) {
+	switch {
+	case v.Attribute != nil:
+		expr, diags := foldExpr(toHCLSyntaxExpression(v.Attribute.Expr), ctx, fold)
+		if diags.HasErrors() {
+			return v, diags
+		}
+
+		attr := *v.Attribute
+		attr.Expr = expr
+
+		return Value{Attribute: &attr}, diags
+	case v.Block != nil:
+		b, ok := v.Block.Body.(*hclsyntax.Body)
+		if !ok {
+			return v, nil
+		}
+
+		body, diags := foldBody(b, ctx, fold)
+		if diags.HasErrors() {
+			return v, diags
+		}
+
+		blk := *v.Block
+		blk.Body = body
+
+		return Value{Block: &blk}, diags
+	}
+
+	return v, nil
+}
+
+// foldBody is the same as foldValue, but for the given hclsyntax.Body.
+func foldBody(b *hclsyntax.Body, ctx *hcl.EvalContext, fold foldFunc) (*hclsyntax.Body, hcl.Diagnostics) {
+	var (
+		nb    = *b
+		diags hcl.Diagnostics
+	)
+
+	nb.Attributes = make(hclsyntax.Attributes, len(b.Attributes))
+
+	for n, attr := range b.Attributes {
+		expr, eDiags := foldExpr(attr.Expr, ctx, fold)
+		diags = diags.Extend(eDiags)
+
+		na := *attr
+		na.Expr = expr
+		nb.Attributes[n] = &na
+	}
+
+	nb.Blocks = make(hclsyntax.Blocks, 0, len(b.Blocks))
+
+	for _, blk := range b.Blocks {
+		body, bDiags := foldBody(blk.Body, ctx, fold)
+		diags = diags.Extend(bDiags)
+
+		nblk := *blk
+		nblk.Body = body
+		nb.Blocks = append(nb.Blocks, &nblk)
+	}
+
+	return &nb, diags
+}
+
+// foldExpr is the same as foldValue, but for the given expression,
+// the rest of the expression is kept as it is.
+func foldExpr(expr hclsyntax.Expression, ctx *hcl.EvalContext, fold foldFunc) (hclsyntax.Expression, hcl.Diagnostics) {
+	if expr == nil {
+		return nil, nil
+	}
+
+	if fold(expr) {
+		v, diags := expr.Value(ctx)
+		if diags.HasErrors() {
+			return expr, diags
+		}
+
+		// Leave the unknown value to Terraform.
+		if !v.IsWhollyKnown() {
+			return expr, nil
+		}
+
+		return exprForValue(v, expr.Range()), nil
+	}
+
+	var diags hcl.Diagnostics
+
+	sub := func(e hclsyntax.Expression) hclsyntax.Expression {
+		ne, eDiags := foldExpr(e, ctx, fold)
+		diags = diags.Extend(eDiags)
+
+		return ne
+	}
+
+	switch e := expr.(type) {
+	case *hclsyntax.RelativeTraversalExpr:
+		ne := *e
+		ne.Source = sub(e.Source)
+
+		return &ne, diags
+	case *hclsyntax.TemplateExpr:
+		ne := *e
+		ne.Parts = make([]hclsyntax.Expression, len(e.Parts))
+
+		for i := range e.Parts {
+			ne.Parts[i] = sub(e.Parts[i])
+
+			// Keep the folded parts as template literals.
+			if lv, ok := ne.Parts[i].(*hclsyntax.LiteralValueExpr); ok && lv.Val.Type().IsPrimitiveType() {
+				if sv, err := convert.Convert(lv.Val, cty.String); err == nil && sv.IsKnown() && !sv.IsNull() {
+					ne.Parts[i] = &hclsyntax.LiteralValueExpr{Val: sv, SrcRange: lv.SrcRange}
+				}
+			}
+		}
+
+		return &ne, diags
+	case *hclsyntax.TemplateWrapExpr:
+		w := sub(e.Wrapped)
+
+		// Unwrap the folded literal, e.g. "${tap.resource.name}".
+		if _, ok := w.(*hclsyntax.LiteralValueExpr); ok {
+			return w, diags
+		}
+
+		ne := *e
+		ne.Wrapped = w
+
+		return &ne, diags
+	case *hclsyntax.TemplateJoinExpr:
+		ne := *e
+		ne.Tuple = sub(e.Tuple)
+
+		return &ne, diags
+	case *hclsyntax.FunctionCallExpr:
+		ne := *e
+		ne.Args = make([]hclsyntax.Expression, len(e.Args))
+
+		for i := range e.Args {
+			ne.Args[i] = sub(e.Args[i])
+		}
+
+		return &ne, diags
+	case *hclsyntax.BinaryOpExpr:
+		ne := *e
+		ne.LHS, ne.RHS = sub(e.LHS), sub(e.RHS)
+
+		return &ne, diags
+	case *hclsyntax.UnaryOpExpr:
+		ne := *e
+		ne.Val = sub(e.Val)
+
+		return &ne, diags
+	case *hclsyntax.ConditionalExpr:
+		ne := *e
+		ne.Condition, ne.TrueResult, ne.FalseResult = sub(e.Condition), sub(e.TrueResult), sub(e.FalseResult)
+
+		return &ne, diags
+	case *hclsyntax.IndexExpr:
+		ne := *e
+		ne.Collection, ne.Key = sub(e.Collection), sub(e.Key)
+
+		return &ne, diags
+	case *hclsyntax.ParenthesesExpr:
+		ne := *e
+		ne.Expression = sub(e.Expression)
+
+		return &ne, diags
+	case *hclsyntax.TupleConsExpr:
+		ne := *e
+		ne.Exprs = make([]hclsyntax.Expression, len(e.Exprs))
+
+		for i := range e.Exprs {
+			ne.Exprs[i] = sub(e.Exprs[i])
+		}
+
+		return &ne, diags
+	case *hclsyntax.ObjectConsExpr:
+		ne := *e
+		ne.Items = make([]hclsyntax.ObjectConsItem, len(e.Items))
+
+		for i := range e.Items {
+			ne.Items[i] = hclsyntax.ObjectConsItem{
+				KeyExpr:   sub(e.Items[i].KeyExpr),
+				ValueExpr: sub(e.Items[i].ValueExpr),
+			}
+		}
+
+		return &ne, diags
+	case *hclsyntax.ObjectConsKeyExpr:
+		// Keep the literal key, e.g. tap = "x".
+		if !e.ForceNonLiteral && hcl.ExprAsKeyword(e.Wrapped) != "" {
+			return e, nil
+		}
+
+		ne := *e
+		ne.Wrapped = sub(e.Wrapped)
+
+		return &ne, diags
+	case *hclsyntax.ForExpr:
+		ne := *e
+		ne.CollExpr, ne.KeyExpr, ne.ValExpr, ne.CondExpr = sub(e.CollExpr), sub(e.KeyExpr), sub(e.ValExpr), sub(e.CondExpr)
+
+		return &ne, diags
+	case *hclsyntax.SplatExpr:
+		ne := *e
+		ne.Source = sub(e.Source)
+
+		return &ne, diags
+	}
+
+	return expr, nil
+}
+
+// exprForValue returns the literal expression of the given value,
+// the object and map values are constructed as object expressions,
+// the tuple, list and set values are constructed as tuple expressions.
+func exprForValue(v cty.Value, rng hcl.Range) hclsyntax.Expression {
+	ty := v.Type()
+
+	switch {
+	case v.IsNull() || !v.IsKnown() || ty.IsPrimitiveType():
+		return &hclsyntax.LiteralValueExpr{Val: v, SrcRange: rng}
+	case ty.IsObjectType() || ty.IsMapType():
+		e := &hclsyntax.ObjectConsExpr{SrcRange: rng, OpenRange: rng}
+
+		for it := v.ElementIterator(); it.Next(); {
+			k, ev := it.Element()
+
+			var ke hclsyntax.Expression = &hclsyntax.TemplateExpr{
+				Parts:    []hclsyntax.Expression{&hclsyntax.LiteralValueExpr{Val: k, SrcRange: rng}},
+				SrcRange: rng,
+			}
+			if hclsyntax.ValidIdentifier(k.AsString()) {
+				ke = &hclsyntax.ScopeTraversalExpr{
+					Traversal: hcl.Traversal{hcl.TraverseRoot{Name: k.AsString(), SrcRange: rng}},
+					SrcRange:  rng,
+				}
+			}
+
+			e.Items = append(e.Items, hclsyntax.ObjectConsItem{
+				KeyExpr:   &hclsyntax.ObjectConsKeyExpr{Wrapped: ke},
+				ValueExpr: exprForValue(ev, rng),
+			})
+		}
+
+		return e
+	case ty.IsTupleType() || ty.IsListType() || ty.IsSetType():
+		e := &hclsyntax.TupleConsExpr{SrcRange: rng, OpenRange: rng}
+
+		for it := v.ElementIterator(); it.Next(); {
+			_, ev := it.Element()
+			e.Exprs = append(e.Exprs, exprForValue(ev, rng))
+		}
+
+		return e
+	}
+
+	return &hclsyntax.LiteralValueExpr{Val: v, SrcRange: rng}
+}
+
+// bodyForValue returns the body of the given object value,
+// in which the lists of objects are converted into nested blocks,
+// e.g. {container = [{name = "nginx"}]} is converted into a container block,
+// and the rest are converted into attributes.
+func bodyForValue(v cty.Value, rng hcl.Range) *hclsyntax.Body {
+	b := &hclsyntax.Body{
+		Attributes: hclsyntax.Attributes{},
+		SrcRange:   rng,
+		EndRange:   rng,
+	}
+
+	for it := v.ElementIterator(); it.Next(); {
+		k, ev := it.Element()
+		n := k.AsString()
+
+		if !isBlockList(ev) {
+			b.Attributes[n] = &hclsyntax.Attribute{
+				Name:      n,
+				Expr:      exprForValue(ev, rng),
+				SrcRange:  rng,
+				NameRange: rng,
+			}
+
+			continue
+		}
+
+		for eit := ev.ElementIterator(); eit.Next(); {
+			_, bv := eit.Element()
+
+			b.Blocks = append(b.Blocks, &hclsyntax.Block{
+				Type:      n,
+				Body:      bodyForValue(bv, rng),
+				TypeRange: rng,
+			})
+		}
+	}
+
+	return b
+}
+
+// isBlockList returns true if the given value is a non-empty list of objects.
+func isBlockList(v cty.Value) bool {
+	ty := v.Type()
+	if v.IsNull() || !v.IsWhollyKnown() || !ty.IsTupleType() && !ty.IsListType() || v.LengthInt() == 0 {
+		return false
+	}
+
+	for it := v.ElementIterator(); it.Next(); {
+		_, ev := it.Element()
+		if ety := ev.Type(); ev.IsNull() || !ety.IsObjectType() && !ety.IsMapType() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// decodeValueFile decodes the given JSON or YAML file into a value,
+// which is selected by the file extension.
+func decodeValueFile(fn string) (cty.Value, error) {
+	bs, err := os.ReadFile(fn)
+	if err != nil {
+		return cty.NilVal, err
+	}
+
+	var (
+		ty  cty.Type
+		v   cty.Value
+		ext = filepath.Ext(fn)
+	)
+
+	switch ext {
+	default:
+		return cty.NilVal, fmt.Errorf("unsupported file extension %q, select from .json, .yaml or .yml", ext)
+	case ".json":
+		ty, err = ctyjson.ImpliedType(bs)
+		if err == nil {
+			v, err = ctyjson.Unmarshal(bs, ty)
+		}
+	case ".yaml", ".yml":
+		ty, err = ctyyaml.Standard.ImpliedType(bs)
+		if err == nil {
+			v, err = ctyyaml.Standard.Unmarshal(bs, ty)
+		}
+	}
+
+	if err != nil {
+		return cty.NilVal, err
+	}
+
+	return v, nil
+}
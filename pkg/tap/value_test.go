@@ -0,0 +1,82 @@
+package tap
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/terraform/lang"
+	"github.com/stretchr/testify/assert"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestFoldExpr(t *testing.T) {
+	ctx := resourceContext(&hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"var": cty.ObjectVal(map[string]cty.Value{
+				"env": cty.StringVal("dev"),
+			}),
+		},
+		Functions: (&lang.Scope{BaseDir: "."}).Functions(),
+	}, resourceObject("resource", "aws_instance", "web", "aws_instance.web", hcl.Range{Filename: "main.tf"}))
+
+	testCases := []struct {
+		given    string
+		static   string
+		resource string
+	}{
+		{
+			given:    `tap.resource.name`,
+			static:   `"web"`,
+			resource: `"web"`,
+		},
+		{
+			given:    `"${tap.resource.type}-${var.env}"`,
+			static:   `"aws_instance-dev"`,
+			resource: `"aws_instance-${var.env}"`,
+		},
+		{
+			given:    `"${var.region}-${var.env}"`,
+			static:   `"${var.region}-dev"`,
+			resource: `"${var.region}-${var.env}"`,
+		},
+		{
+			given:    `upper(var.env) == "DEV" ? 3 : 1`,
+			static:   `3`,
+			resource: `upper(var.env) == "DEV" ? 3 : 1`,
+		},
+		{
+			given:    `[path.module, upper(var.env), timestamp()]`,
+			static:   `[path.module, "DEV", timestamp()]`,
+			resource: `[path.module, upper(var.env), timestamp()]`,
+		},
+		{
+			given:    `{ env = var.env, tap = tap.resource.mode }`,
+			static:   `{ env = "dev", tap = "resource" }`,
+			resource: `{ env = var.env, tap = "resource" }`,
+		},
+	}
+
+	for _, tc := range testCases {
+		expr, diags := hclsyntax.ParseExpression([]byte(tc.given), "", hcl.InitialPos)
+		if diags.HasErrors() {
+			t.Fatalf("failed to parse %s: %v", tc.given, diags)
+		}
+
+		for _, c := range []struct {
+			fold     foldFunc
+			expected string
+		}{
+			{fold: foldsStatic(ctx), expected: tc.static},
+			{fold: foldsTap, expected: tc.resource},
+		} {
+			actual, diags := foldExpr(expr, ctx, c.fold)
+			if !assert.False(t, diags.HasErrors(), tc.given) {
+				continue
+			}
+
+			ee, _ := hclsyntax.ParseExpression([]byte(c.expected), "", hcl.InitialPos)
+			assert.Equal(t, string(exprBytes(ee)), string(exprBytes(actual)), tc.given)
+		}
+	}
+}
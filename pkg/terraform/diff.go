@@ -0,0 +1,206 @@
+package terraform
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ChangeAction is the action of a Change.
+type ChangeAction string
+
+const (
+	ChangeCreate ChangeAction = "create"
+	ChangeDelete ChangeAction = "delete"
+	ChangeUpdate ChangeAction = "update"
+)
+
+// Change is a difference of a value between two configurations,
+// the values are encoded in the same way as Terraform JSON syntax,
+// in which the nested blocks are arrays of objects.
+type Change struct {
+	// Path consists of the object keys in string and the array indexes in int.
+	Path   []any
+	Action ChangeAction
+	// Before is nil if the Action is ChangeCreate.
+	Before any
+	// After is nil if the Action is ChangeDelete.
+	After any
+}
+
+// PathString returns the path in the form of "a.b[0].c".
+func (c Change) PathString() string {
+	var sb strings.Builder
+
+	for _, p := range c.Path {
+		switch v := p.(type) {
+		case int:
+			fmt.Fprintf(&sb, "[%d]", v)
+		default:
+			if sb.Len() != 0 {
+				sb.WriteByte('.')
+			}
+
+			fmt.Fprintf(&sb, "%v", v)
+		}
+	}
+
+	return sb.String()
+}
+
+// String returns the change in the form of "path: expected X, got Y".
+func (c Change) String() string {
+	switch c.Action {
+	case ChangeDelete:
+		return fmt.Sprintf("%s: lost, expected %s", c.PathString(), summaryString(c.Before))
+	case ChangeCreate:
+		return fmt.Sprintf("%s: unexpected %s", c.PathString(), summaryString(c.After))
+	}
+
+	return fmt.Sprintf("%s: expected %s, got %s",
+		c.PathString(), summaryString(c.Before), summaryString(c.After))
+}
+
+// ObjectChanges holds the changes of an object,
+// the paths of the changes are relative to the object.
+type ObjectChanges struct {
+	// Address is the address of the object,
+	// e.g. "aws_instance.web", "data.aws_ami.ubuntu", "var.name", "output.id", "module.vpc",
+	// "provider.aws", "check.health" or "locals".
+	Address string
+	Changes []Change
+}
+
+// Diff compares the root modules of the given configurations semantically,
+// returns the changes grouped by the object in address order.
+func Diff(before, after *Config) ([]ObjectChanges, error) {
+	bs, err := summarizeModule(before.Root.Module)
+	if err != nil {
+		return nil, fmt.Errorf("diff: error summarizing configuration before: %w", err)
+	}
+
+	as, err := summarizeModule(after.Root.Module)
+	if err != nil {
+		return nil, fmt.Errorf("diff: error summarizing configuration after: %w", err)
+	}
+
+	// Compare the objects one by one.
+	prefixes := map[string]string{
+		"resource": "",
+		"data":     "",
+		"variable": "var.",
+		"output":   "output.",
+		"module":   "module.",
+		"provider": "provider.",
+		"check":    "check.",
+	}
+
+	var r []ObjectChanges
+
+	for _, typ := range sortedKeys(mergeKeys(bs, as)) {
+		prefix, named := prefixes[typ]
+		if !named {
+			if cs := diffObject(bs, as, typ); len(cs) != 0 {
+				r = append(r, ObjectChanges{Address: typ, Changes: cs})
+			}
+
+			continue
+		}
+
+		bos, _ := bs[typ].(map[string]any)
+		aos, _ := as[typ].(map[string]any)
+
+		for _, n := range sortedKeys(mergeKeys(bos, aos)) {
+			if cs := diffObject(bos, aos, n); len(cs) != 0 {
+				r = append(r, ObjectChanges{Address: prefix + n, Changes: cs})
+			}
+		}
+	}
+
+	sort.SliceStable(r, func(i, j int) bool {
+		return r[i].Address < r[j].Address
+	})
+
+	return r, nil
+}
+
+// diffObject returns the changes of the object with the given key between the given summaries,
+// the created or deleted object is a change with an empty path.
+func diffObject(before, after map[string]any, key string) []Change {
+	bv, bok := before[key]
+	av, aok := after[key]
+
+	switch {
+	case !aok:
+		return []Change{{Action: ChangeDelete, Before: bv}}
+	case !bok:
+		return []Change{{Action: ChangeCreate, After: av}}
+	}
+
+	var cs []Change
+	diffValues(&cs, nil, bv, av)
+
+	return cs
+}
+
+// mergeKeys returns a map holding the keys of the given maps.
+func mergeKeys(ms ...map[string]any) map[string]struct{} {
+	r := map[string]struct{}{}
+
+	for _, m := range ms {
+		for k := range m {
+			r[k] = struct{}{}
+		}
+	}
+
+	return r
+}
+
+// diffValues appends the changes between the given JSON-like values into the given changes,
+// each change is prefixed with the path.
+func diffValues(changes *[]Change, path []any, before, after any) {
+	at := func(p any) []any {
+		return append(append(make([]any, 0, len(path)+1), path...), p)
+	}
+
+	switch b := before.(type) {
+	case map[string]any:
+		a, ok := after.(map[string]any)
+		if !ok {
+			break
+		}
+
+		for _, k := range sortedKeys(mergeKeys(b, a)) {
+			bv, bok := b[k]
+			av, aok := a[k]
+
+			switch {
+			case !aok:
+				*changes = append(*changes, Change{Path: at(k), Action: ChangeDelete, Before: bv})
+			case !bok:
+				*changes = append(*changes, Change{Path: at(k), Action: ChangeCreate, After: av})
+			default:
+				diffValues(changes, at(k), bv, av)
+			}
+		}
+
+		return
+	case []any:
+		a, ok := after.([]any)
+		if !ok || len(b) != len(a) {
+			break
+		}
+
+		for i := range b {
+			diffValues(changes, at(i), b[i], a[i])
+		}
+
+		return
+	default:
+		if summaryString(before) == summaryString(after) {
+			return
+		}
+	}
+
+	*changes = append(*changes, Change{Path: path, Action: ChangeUpdate, Before: before, After: after})
+}
@@ -0,0 +1,59 @@
+package terraform
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	dir := filepath.Join("testdata", "load", "with_override")
+
+	before, err := Load(dir)
+	if err != nil {
+		t.Fatalf("failed to load terraform configuration: %v", err)
+	}
+
+	after, err := Load(dir)
+	if err != nil {
+		t.Fatalf("failed to load terraform configuration: %v", err)
+	}
+
+	// Nothing changed.
+	ocs, err := Diff(before, after)
+	if assert.NoError(t, err) {
+		assert.Len(t, ocs, 0)
+	}
+
+	// Delete a resource, a meta-argument and a local.
+	am := after.Root.Module
+	delete(am.ManagedResources, "kubernetes_secret_v1.secret")
+	delete(am.ManagedResources["kubernetes_config_map_v1.config"].Config.(*hclsyntax.Body).Attributes, "count")
+	delete(am.Locals, "name")
+
+	ocs, err = Diff(before, after)
+	if !assert.NoError(t, err) || !assert.Len(t, ocs, 3) {
+		return
+	}
+
+	assert.Equal(t, "kubernetes_config_map_v1.config", ocs[0].Address)
+	if assert.Len(t, ocs[0].Changes, 1) {
+		assert.Equal(t, ChangeDelete, ocs[0].Changes[0].Action)
+		assert.Equal(t, "count", ocs[0].Changes[0].PathString())
+		assert.Equal(t, float64(1), ocs[0].Changes[0].Before)
+	}
+
+	assert.Equal(t, "kubernetes_secret_v1.secret", ocs[1].Address)
+	if assert.Len(t, ocs[1].Changes, 1) {
+		assert.Equal(t, ChangeDelete, ocs[1].Changes[0].Action)
+		assert.Equal(t, "", ocs[1].Changes[0].PathString())
+	}
+
+	assert.Equal(t, "locals", ocs[2].Address)
+	if assert.Len(t, ocs[2].Changes, 1) {
+		assert.Equal(t, "name", ocs[2].Changes[0].PathString())
+		assert.Equal(t, "nginx", ocs[2].Changes[0].Before)
+	}
+}
@@ -0,0 +1,47 @@
+package terraform
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Flavor indicates which CLI is delegated to,
+// the writer emits some settings only for a specific flavor.
+type Flavor string
+
+const (
+	FlavorTerraform Flavor = "terraform"
+	FlavorOpenTofu  Flavor = "tofu"
+)
+
+// FlavorOf returns the Flavor of the given executable binary path,
+// defaults to FlavorTerraform.
+func FlavorOf(bin string) Flavor {
+	n := strings.TrimSuffix(filepath.Base(bin), filepath.Ext(bin))
+	if n == string(FlavorOpenTofu) {
+		return FlavorOpenTofu
+	}
+
+	return FlavorTerraform
+}
+
+// FlavorOfDir returns FlavorOpenTofu if the given directory contains the .tofu or .tofu.json files,
+// otherwise returns the empty Flavor, since the flavor cannot be told by the .tf files.
+func FlavorOfDir(dir string) Flavor {
+	for _, pattern := range []string{"*.tofu", "*.tofu.json"} {
+		if ms, _ := filepath.Glob(filepath.Join(dir, pattern)); len(ms) != 0 {
+			return FlavorOpenTofu
+		}
+	}
+
+	return ""
+}
+
+// fileExt returns the configuration file extension of the given flavor.
+func fileExt(f Flavor) string {
+	if f == FlavorOpenTofu {
+		return ".tofu"
+	}
+
+	return ".tf"
+}
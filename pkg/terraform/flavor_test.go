@@ -0,0 +1,39 @@
+package terraform
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlavorOf(t *testing.T) {
+	testCases := []struct {
+		bin      string
+		expected Flavor
+	}{
+		{bin: "/usr/local/bin/terraform", expected: FlavorTerraform},
+		{bin: "/usr/local/bin/tofu", expected: FlavorOpenTofu},
+		{bin: "tofu.exe", expected: FlavorOpenTofu},
+		{bin: "tf", expected: FlavorTerraform},
+	}
+
+	for _, tc := range testCases {
+		assert.Equalf(t, tc.expected, FlavorOf(tc.bin), "flavor of %s", tc.bin)
+	}
+}
+
+func TestFlavorOfDir(t *testing.T) {
+	testCases := []struct {
+		dir      string
+		expected Flavor
+	}{
+		{dir: filepath.Join("testdata", "load", "tofu_with_tofu_files"), expected: FlavorOpenTofu},
+		{dir: filepath.Join("testdata", "load", "tofu_with_encryption"), expected: ""},
+		{dir: filepath.Join("testdata", "load", "normal"), expected: ""},
+	}
+
+	for _, tc := range testCases {
+		assert.Equalf(t, tc.expected, FlavorOfDir(tc.dir), "flavor of %s", tc.dir)
+	}
+}
@@ -13,13 +13,40 @@ import (
 
 type Config = configs.Config
 
+// LoadOptions holds the options of Load.
+type LoadOptions struct {
+	// Flavor is the flavor of the delegated CLI, defaults to FlavorTerraform.
+	Flavor Flavor
+}
+
+// LoadOption configures the LoadOptions.
+type LoadOption func(*LoadOptions)
+
+// WithLoadFlavor configures the flavor of the delegated CLI,
+// with FlavorOpenTofu, the .tofu and .tofu.json files are loaded as well,
+// and take precedence over the .tf and .tf.json files with the same name.
+func WithLoadFlavor(f Flavor) LoadOption {
+	return func(o *LoadOptions) {
+		o.Flavor = f
+	}
+}
+
 // Load loads the terraform configuration from the given directory,
 // applying overrides to the configuration as necessary.
-func Load(dir string) (*Config, error) {
+func Load(dir string, opts ...LoadOption) (*Config, error) {
+	o := LoadOptions{
+		Flavor: FlavorTerraform,
+	}
+
+	for i := range opts {
+		opts[i](&o)
+	}
+
 	fs := afero.Afero{Fs: afero.NewBasePathFs(afero.NewOsFs(), dir)}
 
 	parser := configs.NewParser(fs)
 	parser.AllowLanguageExperiments(true)
+	parser.AllowOpenTofuFiles(o.Flavor == FlavorOpenTofu)
 
 	var walker configs.ModuleWalkerFunc
 	{
@@ -57,5 +84,7 @@ func Load(dir string) (*Config, error) {
 		return nil, fmt.Errorf("failed to apply overrides: %w", err)
 	}
 
+	cfg = convertJSONSyntax(cfg)
+
 	return cfg, nil
 }
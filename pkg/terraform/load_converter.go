@@ -0,0 +1,70 @@
+package terraform
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	hcljson "github.com/hashicorp/hcl/v2/json"
+	"github.com/hashicorp/terraform/configs"
+)
+
+// convertJSONSyntax converts the bodies and expressions of the root module declared in JSON syntax
+// into HCL native syntax, so that they can be patched in the same way.
+//
+// The converted bodies are attribute-only,
+// see ToJSONBody to convert them back for decoding.
+func convertJSONSyntax(cfg *Config) *Config {
+	rm := cfg.Root.Module
+
+	if rm.Backend != nil {
+		rm.Backend.Config = convertJSONBody(rm.Backend.Config)
+	}
+
+	if rm.CloudConfig != nil {
+		rm.CloudConfig.Config = convertJSONBody(rm.CloudConfig.Config)
+	}
+
+	if rm.Encryption != nil {
+		rm.Encryption.Config = convertJSONBody(rm.Encryption.Config)
+	}
+
+	for _, pm := range rm.ProviderMetas {
+		pm.Config = convertJSONBody(pm.Config)
+	}
+
+	for _, p := range rm.ProviderConfigs {
+		p.Config = convertJSONBody(p.Config)
+	}
+
+	for _, v := range rm.Variables {
+		v.Config = convertJSONBody(v.Config)
+	}
+
+	for _, l := range rm.Locals {
+		if e, ok := hcljson.ToSyntaxExpression(l.Expr); ok {
+			l.Expr = e
+		}
+	}
+
+	for _, o := range rm.Outputs {
+		o.Config = convertJSONBody(o.Config)
+	}
+
+	for _, mc := range rm.ModuleCalls {
+		mc.Config = convertJSONBody(mc.Config)
+	}
+
+	for _, ress := range []map[string]*configs.Resource{rm.ManagedResources, rm.DataResources} {
+		for _, r := range ress {
+			r.Config = convertJSONBody(r.Config)
+		}
+	}
+
+	return cfg
+}
+
+func convertJSONBody(body hcl.Body) hcl.Body {
+	if sb, ok := hcljson.ToSyntaxBody(body); ok {
+		return sb
+	}
+
+	return body
+}
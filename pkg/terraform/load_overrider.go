@@ -5,10 +5,17 @@ import (
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
+	hcljson "github.com/hashicorp/hcl/v2/json"
 	"github.com/hashicorp/terraform/configs"
 )
 
-// mergeOverrides applies the overrides into the given configuration.
+// mergeOverrides applies the overrides into the given configuration,
+// flattens the merged bodies of all block kinds,
+// so that the configuration can be patched and written without the override files.
+//
+// The terraform settings, like backend, cloud and required_providers,
+// have been replaced by the override files during loading,
+// which are written by regenerating the terraform block.
 func mergeOverrides(cfg *Config) (*Config, error) {
 	var err error
 
@@ -30,6 +37,87 @@ func mergeOverrides(cfg *Config) (*Config, error) {
 		rm.DataResources = ress
 	}
 
+	for pn, p := range rm.ProviderConfigs {
+		if p.Config == nil {
+			continue
+		}
+
+		var diags hcl.Diagnostics
+
+		p.Config, diags = mergeBody(p.Config)
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("error merging provider %q: %w", pn, diags)
+		}
+	}
+
+	for mcn, mc := range rm.ModuleCalls {
+		if mc.Config == nil {
+			continue
+		}
+
+		var diags hcl.Diagnostics
+
+		mc.Config, diags = mergeBody(mc.Config)
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("error merging module call %q: %w", mcn, diags)
+		}
+	}
+
+	// Since the variables and outputs are merged in fields,
+	// merge their bodies with the override files.
+	var overrides []configs.SourceFile
+
+	for _, sf := range rm.SourceFiles {
+		if sf.Override {
+			overrides = append(overrides, sf)
+		}
+	}
+
+	if len(overrides) == 0 {
+		return cfg, nil
+	}
+
+	ofs, err := loadSourceFiles(overrides)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, sf := range overrides {
+		for _, ov := range ofs[sf.Name].Variables {
+			v := rm.Variables[ov.Name]
+			if v == nil || v.Config == nil {
+				continue
+			}
+
+			var diags hcl.Diagnostics
+
+			v.Config, diags = mergeAttributes(v.Config, ov.Config)
+			if diags.HasErrors() {
+				return nil, fmt.Errorf("error merging variable %q: %w", ov.Name, diags)
+			}
+
+			// Write the merged variable in structure.
+			v.Tokens = nil
+		}
+
+		for _, oo := range ofs[sf.Name].Outputs {
+			o := rm.Outputs[oo.Name]
+			if o == nil || o.Config == nil {
+				continue
+			}
+
+			var diags hcl.Diagnostics
+
+			o.Config, diags = mergeAttributes(o.Config, oo.Config)
+			if diags.HasErrors() {
+				return nil, fmt.Errorf("error merging output %q: %w", oo.Name, diags)
+			}
+
+			// Write the merged output in structure.
+			o.Tokens = nil
+		}
+	}
+
 	return cfg, nil
 }
 
@@ -52,11 +140,50 @@ func mergeResources(ress map[string]*configs.Resource) (map[string]*configs.Reso
 	return ress, nil
 }
 
+// mergeAttributes merges the attributes of the given override body into the given base body,
+// the blocks of the override body are ignored,
+// as Terraform does not override the validation blocks of variables and the precondition blocks of outputs.
+func mergeAttributes(base, override hcl.Body) (hcl.Body, hcl.Diagnostics) {
+	if override == nil {
+		return base, nil
+	}
+
+	ob, ok := override.(*hclsyntax.Body)
+	if !ok {
+		ob, ok = hcljson.ToSyntaxBody(override)
+	}
+
+	if !ok {
+		return nil, hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid override body",
+				Detail:   fmt.Sprintf("Expected *hclsyntax.Body, got %T", override),
+				Subject:  override.MissingItemRange().Ptr(),
+			},
+		}
+	}
+
+	return mergeBody(configs.MergeBody{
+		Base: base,
+		Override: &hclsyntax.Body{
+			Attributes: ob.Attributes,
+			SrcRange:   ob.SrcRange,
+			EndRange:   ob.EndRange,
+		},
+	})
+}
+
 func mergeBody(body hcl.Body) (hcl.Body, hcl.Diagnostics) {
 	var b *configs.MergeBody
 
 	switch t := body.(type) {
 	default:
+		// Convert the JSON body to merge with the HCL native body.
+		if sb, ok := hcljson.ToSyntaxBody(body); ok {
+			return sb, nil
+		}
+
 		return body, nil
 	case *configs.MergeBody:
 		b = t
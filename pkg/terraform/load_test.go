@@ -5,7 +5,10 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/stretchr/testify/assert"
+	"github.com/zclconf/go-cty/cty"
 )
 
 func TestLoad(t *testing.T) {
@@ -21,7 +24,82 @@ func TestLoad(t *testing.T) {
 			continue
 		}
 
-		_, err := Load(filepath.Join(testCasesDataDir, tc.Name()))
+		_, err := Load(filepath.Join(testCasesDataDir, tc.Name()), WithLoadFlavor(testCaseFlavor(tc.Name())))
 		assert.NoError(t, err)
 	}
 }
+
+func TestLoad_withOverride(t *testing.T) {
+	cfg, err := Load(filepath.Join("testdata", "load", "with_override"))
+	if err != nil {
+		t.Fatalf("failed to load terraform configuration: %v", err)
+	}
+
+	m := cfg.Root.Module
+
+	// The merged bodies are flattened.
+	attrValue := func(body hcl.Body, name string) cty.Value {
+		t.Helper()
+
+		b, ok := body.(*hclsyntax.Body)
+		if !assert.Truef(t, ok, "expected flattened body, got %T", body) {
+			return cty.NilVal
+		}
+
+		attr, exist := b.Attributes[name]
+		if !assert.Truef(t, exist, "expected attribute %q", name) {
+			return cty.NilVal
+		}
+
+		v, diags := attr.Expr.Value(nil)
+		assert.Falsef(t, diags.HasErrors(), "evaluate attribute %q", name)
+
+		return v
+	}
+
+	// Backend is replaced by cloud.
+	assert.Nil(t, m.Backend)
+	assert.NotNil(t, m.CloudConfig)
+
+	// Provider arguments are merged.
+	p := m.ProviderConfigs["kubernetes"]
+	if assert.NotNil(t, p) {
+		assert.Equal(t, cty.StringVal("~/.kube/config"), attrValue(p.Config, "config_path"))
+		assert.Equal(t, cty.StringVal("override"), attrValue(p.Config, "config_context"))
+	}
+
+	// Module inputs are merged.
+	mc := m.ModuleCalls["nginx"]
+	if assert.NotNil(t, mc) {
+		assert.Contains(t, mc.Config.(*hclsyntax.Body).Attributes, "name")
+		assert.Equal(t, cty.StringVal("override"), attrValue(mc.Config, "namespace"))
+	}
+
+	// Variable arguments are merged, but the validation blocks are kept.
+	v := m.Variables["replicas"]
+	if assert.NotNil(t, v) {
+		assert.True(t, cty.NumberIntVal(2).RawEquals(v.Default))
+		assert.True(t, cty.NumberIntVal(2).RawEquals(attrValue(v.Config, "default")))
+		assert.Len(t, v.Config.(*hclsyntax.Body).Blocks, 1)
+		assert.Nil(t, v.Tokens)
+	}
+
+	// Output arguments are merged.
+	o := m.Outputs["name"]
+	if assert.NotNil(t, o) {
+		assert.True(t, o.Sensitive)
+		assert.Equal(t, cty.True, attrValue(o.Config, "sensitive"))
+		assert.Equal(t, cty.StringVal("The name of the deployment."), attrValue(o.Config, "description"))
+		assert.Nil(t, o.Tokens)
+	}
+
+	// Resource arguments are merged, the last override file wins.
+	r := m.ManagedResources["kubernetes_deployment_v1.deploy"]
+	if assert.NotNil(t, r) {
+		b := r.Config.(*hclsyntax.Body)
+		if assert.Len(t, b.Blocks, 2) {
+			assert.Equal(t, "metadata", b.Blocks[1].Type)
+			assert.Equal(t, cty.StringVal("override"), attrValue(b.Blocks[1].Body, "name"))
+		}
+	}
+}
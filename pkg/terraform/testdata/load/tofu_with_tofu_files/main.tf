@@ -0,0 +1,7 @@
+# This file is ignored by OpenTofu, since main.tofu exists.
+
+resource "null_resource" "example" {
+  triggers = {
+    runtime = "terraform"
+  }
+}
@@ -0,0 +1,19 @@
+terraform {
+  required_providers {
+    kubernetes = {
+      source  = "hashicorp/kubernetes"
+      version = ">= 2.23.0"
+    }
+  }
+}
+
+provider "kubernetes" {
+  config_path = "~/.kube/config"
+}
+
+# The namespace is generated as JSON.
+resource "kubernetes_namespace_v1" "ns" {
+  metadata {
+    name = var.namespace
+  }
+}
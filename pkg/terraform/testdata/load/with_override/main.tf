@@ -11,10 +11,15 @@ terraform {
   backend "http" {
     address = "http://myrest.api.com/foo"
   }
+
+  provider_meta "kubernetes" {
+    module_name = "example"
+  }
 }
 
 provider "kubernetes" {
-  config_path = "~/.kube/config"
+  config_path    = "~/.kube/config"
+  config_context = "default"
 }
 
 locals {
@@ -97,3 +102,25 @@ resource "kubernetes_secret_v1" "secret" {
     "k2" = "v2"
   }
 }
+
+variable "replicas" {
+  type        = number
+  description = "The replicas of the deployment."
+  default     = 1
+
+  validation {
+    condition     = var.replicas > 0
+    error_message = "The replicas must be greater than 0."
+  }
+}
+
+module "nginx" {
+  source = "./modules/nginx"
+
+  name      = local.name
+  namespace = local.namespace
+}
+
+output "name" {
+  value = kubernetes_deployment_v1.deploy.metadata[0].name
+}
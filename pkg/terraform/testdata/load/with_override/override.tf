@@ -32,3 +32,31 @@ resource "kubernetes_config_map_v1" "config" {
   # override meta argument.
   count = 1
 }
+
+resource "kubernetes_secret_v1" "secret" {
+  # override meta argument, which is not declared in the base.
+  lifecycle {
+    ignore_changes = [data]
+  }
+}
+
+provider "kubernetes" {
+  # override provider argument.
+  config_context = "override"
+}
+
+variable "replicas" {
+  # override variable argument.
+  default = 2
+}
+
+module "nginx" {
+  # override module input.
+  namespace = "override"
+}
+
+output "name" {
+  # override output argument.
+  description = "The name of the deployment."
+  sensitive   = true
+}
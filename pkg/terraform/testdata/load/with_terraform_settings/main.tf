@@ -0,0 +1,23 @@
+terraform {
+  required_version = ">= 1.5"
+
+  required_providers {
+    aws = {
+      source                = "hashicorp/aws"
+      version               = ">= 5.0"
+      configuration_aliases = [aws.west, aws.east]
+    }
+  }
+}
+
+resource "aws_s3_bucket" "west" {
+  provider = aws.west
+
+  bucket = "west"
+}
+
+resource "aws_s3_bucket" "east" {
+  provider = aws.east
+
+  bucket = "east"
+}
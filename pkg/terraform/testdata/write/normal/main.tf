@@ -67,4 +67,3 @@ resource "kubernetes_service_v1" "svc" {
     }
   }
 }
-
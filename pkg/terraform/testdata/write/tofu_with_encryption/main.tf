@@ -0,0 +1,22 @@
+terraform {
+  required_version = ">= 1.7"
+
+  encryption {
+    key_provider "pbkdf2" "default" {
+      passphrase = var.passphrase
+    }
+
+    method "aes_gcm" "default" {
+      keys = key_provider.pbkdf2.default
+    }
+
+    state {
+      method = method.aes_gcm.default
+    }
+  }
+}
+
+variable "passphrase" {
+  type      = string
+  sensitive = true
+}
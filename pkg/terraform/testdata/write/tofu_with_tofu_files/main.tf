@@ -0,0 +1,25 @@
+terraform {
+  required_version = ">= 1.6"
+}
+
+# This file takes precedence over main.tf.
+
+resource "null_resource" "example" {
+  triggers = {
+    runtime = "tofu"
+    name    = var.name
+  }
+}
+
+{
+  "output": {
+    "id": {
+      "value": "${null_resource.example.id}"
+    }
+  }
+}
+
+variable "name" {
+  type    = string
+  default = "example"
+}
@@ -0,0 +1,20 @@
+# The deployment of nginx.
+resource "kubernetes_deployment_v1" "deploy" {
+  metadata {
+    name = "nginx" # inline comment
+    annotations = {
+      # The description is kept as heredoc.
+      description = <<-EOT
+        Nginx deployment,
+        which is patched by TAP.
+      EOT
+    }
+  }
+
+  /*
+   * The spec of the deployment.
+   */
+  spec {
+    replicas = 1
+  }
+}
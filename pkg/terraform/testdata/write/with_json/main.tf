@@ -0,0 +1,71 @@
+{
+  "//": "Generated, do not edit.",
+  "variable": {
+    "namespace": {
+      "type": "string",
+      "default": "default"
+    }
+  },
+  "locals": {
+    "name": "nginx",
+    "labels": {
+      "app": "${local.name}"
+    }
+  },
+  "resource": {
+    "kubernetes_deployment_v1": {
+      "deployment": {
+        "count": 1,
+        "metadata": [
+          {
+            "name": "${local.name}",
+            "namespace": "${kubernetes_namespace_v1.ns.metadata[0].name}",
+            "labels": "${local.labels}"
+          }
+        ],
+        "spec": [
+          {
+            "replicas": 1,
+            "selector": [
+              {
+                "match_labels": "${local.labels}"
+              }
+            ]
+          }
+        ],
+        "lifecycle": {
+          "ignore_changes": [
+            "spec[0].replicas"
+          ]
+        },
+        "wait_for_rollout": false
+      }
+    }
+  },
+  "output": {
+    "name": {
+      "value": "${kubernetes_deployment_v1.deployment[0].metadata[0].name}"
+    }
+  }
+}
+
+terraform {
+  required_providers {
+    kubernetes = {
+      source  = "hashicorp/kubernetes"
+      version = ">= 2.23.0"
+    }
+  }
+}
+
+provider "kubernetes" {
+  config_path = "~/.kube/config"
+}
+
+# The namespace is generated as JSON.
+resource "kubernetes_namespace_v1" "ns" {
+  metadata {
+    name = var.namespace
+  }
+  wait_for_default_service_account = true
+}
@@ -0,0 +1,77 @@
+terraform {
+  required_version = ">= 1.0"
+
+  required_providers {
+    aws = {
+      source  = "hashicorp/aws"
+      version = ">= 5.0.0"
+    }
+  }
+}
+
+provider "aws" {
+  alias  = "west"
+  region = "us-west-2"
+}
+
+data "aws_ami" "ubuntu" {
+  provider    = aws.west
+  most_recent = true
+
+  lifecycle {
+    postcondition {
+      condition     = self.architecture == "x86_64"
+      error_message = "The AMI must be for the x86_64 architecture."
+    }
+  }
+}
+
+resource "aws_instance" "web" {
+  for_each = toset(["a", "b"])
+  provider = aws.west
+
+  ami           = data.aws_ami.ubuntu.id
+  instance_type = "t3.micro"
+
+  depends_on = [data.aws_ami.ubuntu]
+
+  lifecycle {
+    create_before_destroy = true
+    prevent_destroy       = false
+    ignore_changes        = [tags["Name"], ami]
+    replace_triggered_by  = [null_resource.trigger.id]
+
+    precondition {
+      condition     = data.aws_ami.ubuntu.architecture == "x86_64"
+      error_message = "The AMI must be for the x86_64 architecture."
+    }
+  }
+
+  connection {
+    type = "ssh"
+    host = self.public_ip
+  }
+
+  provisioner "local-exec" {
+    command = "echo ${self.private_ip}"
+  }
+
+  provisioner "remote-exec" {
+    when       = destroy
+    on_failure = continue
+    inline     = ["echo bye"]
+
+    connection {
+      type = "ssh"
+      host = self.public_ip
+    }
+  }
+}
+
+resource "null_resource" "trigger" {
+  count = 1
+
+  lifecycle {
+    ignore_changes = all
+  }
+}
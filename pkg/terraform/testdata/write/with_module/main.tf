@@ -18,6 +18,14 @@ locals {
   name      = "nginx"
 }
 
+module "nginx" {
+  count  = local.namespace == "default" ? 1 : 0
+  source = "./modules/nginx"
+
+  name      = local.name
+  namespace = local.namespace
+}
+
 resource "kubernetes_service_v1" "svc" {
   metadata {
     name      = local.name
@@ -32,12 +40,3 @@ resource "kubernetes_service_v1" "svc" {
     }
   }
 }
-
-module "nginx" {
-  source = "./modules/nginx"
-
-  count     = local.namespace == "default" ? 1 : 0
-  name      = local.name
-  namespace = local.namespace
-}
-
@@ -0,0 +1,53 @@
+terraform {
+  required_providers {
+    aws = {
+      source  = "hashicorp/aws"
+      version = ">= 5.0"
+    }
+  }
+}
+
+provider "aws" {
+  region = "us-east-1"
+}
+
+provider "aws" {
+  alias  = "west"
+  region = "us-west-2"
+}
+
+variable "buckets" {
+  type    = set(string)
+  default = ["logs", "assets"]
+}
+
+resource "aws_iam_role" "replication" {
+  name = "replication"
+}
+
+module "bucket" {
+  for_each = var.buckets
+  source   = "terraform-aws-modules/s3-bucket/aws"
+  version  = "~> 3.15"
+
+  providers = {
+    aws = aws.west
+  }
+
+  bucket = each.key
+
+  depends_on = [aws_iam_role.replication]
+}
+
+module "vpc" {
+  source  = "terraform-aws-modules/vpc/aws"
+  version = ">= 5.0, < 6.0"
+  count   = 2
+
+  providers = {
+    aws      = aws
+    aws.peer = aws.west
+  }
+
+  name = "vpc-${count.index}"
+}
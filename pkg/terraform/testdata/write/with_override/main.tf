@@ -14,10 +14,15 @@ terraform {
       version = ">= 2.23.0"
     }
   }
+
+  provider_meta "kubernetes" {
+    module_name = "example"
+  }
 }
 
 provider "kubernetes" {
-  config_path = "~/.kube/config"
+  config_path    = "~/.kube/config"
+  config_context = "override"
 }
 
 locals {
@@ -29,6 +34,11 @@ locals {
 }
 
 resource "kubernetes_deployment_v1" "deploy" {
+  metadata {
+    name      = "override"
+    namespace = "override"
+  }
+
   spec {
     replicas = 1
     selector {
@@ -49,10 +59,6 @@ resource "kubernetes_deployment_v1" "deploy" {
       }
     }
   }
-  metadata {
-    name      = "override"
-    namespace = "override"
-  }
 }
 
 resource "kubernetes_service_v1" "svc" {
@@ -60,6 +66,7 @@ resource "kubernetes_service_v1" "svc" {
     name      = kubernetes_deployment_v1.deploy.metadata[0].name
     namespace = kubernetes_deployment_v1.deploy.metadata[0].namespace
   }
+
   spec {
     selector = local.selectors
     type     = "ClusterIP"
@@ -71,26 +78,54 @@ resource "kubernetes_service_v1" "svc" {
 }
 
 resource "kubernetes_config_map_v1" "config" {
-  data = {
-    "k1" = "v1"
-    "k2" = "v2"
-  }
   count = 1
+
   metadata {
     generate_name = format("%s-", kubernetes_deployment_v1.deploy.metadata[0].name)
     namespace     = kubernetes_deployment_v1.deploy.metadata[0].namespace
   }
+  data = {
+    "k1" = "v1"
+    "k2" = "v2"
+  }
 }
 
 resource "kubernetes_secret_v1" "secret" {
   for_each = [{}]
+
+  metadata {
+    generate_name = format("%s-", kubernetes_deployment_v1.deploy.metadata[0].name)
+    namespace     = kubernetes_deployment_v1.deploy.metadata[0].namespace
+  }
   data = {
     "k1" = "v1"
     "k2" = "v2"
   }
-  metadata {
-    generate_name = format("%s-", kubernetes_deployment_v1.deploy.metadata[0].name)
-    namespace     = kubernetes_deployment_v1.deploy.metadata[0].namespace
+  lifecycle {
+    ignore_changes = [data]
+  }
+}
+
+variable "replicas" {
+  type        = number
+  description = "The replicas of the deployment."
+  default     = 2
+
+  validation {
+    condition     = var.replicas > 0
+    error_message = "The replicas must be greater than 0."
   }
 }
 
+module "nginx" {
+  source = "./modules/nginx"
+
+  name      = local.name
+  namespace = "override"
+}
+
+output "name" {
+  value       = kubernetes_deployment_v1.deploy.metadata[0].name
+  description = "The name of the deployment."
+  sensitive   = true
+}
@@ -0,0 +1,58 @@
+provider "aws" {
+  alias  = "west"
+  region = "us-west-2"
+}
+
+resource "aws_instance" "web" {
+  ami           = "ami-a1b2c3d4"
+  instance_type = "t2.micro"
+}
+
+moved {
+  from = aws_instance.app
+  to   = aws_instance.web
+}
+
+moved {
+  from = module.old
+  to   = module.new["a"]
+}
+
+import {
+  to       = aws_instance.web
+  id       = "i-abcd1234"
+  provider = aws.west
+}
+
+removed {
+  from = aws_instance.legacy
+
+  lifecycle {
+    destroy = false
+  }
+}
+
+removed {
+  from = aws_instance.retired
+
+  connection {
+    type = "ssh"
+    host = self.public_ip
+  }
+
+  provisioner "local-exec" {
+    when    = destroy
+    command = "echo 'Instance ${self.id} has been destroyed.'"
+  }
+}
+
+check "health_check" {
+  data "http" "web" {
+    url = "https://${aws_instance.web.public_ip}"
+  }
+
+  assert {
+    condition     = data.http.web.status_code == 200
+    error_message = "${data.http.web.url} returned an unhealthy status code"
+  }
+}
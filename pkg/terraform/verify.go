@@ -0,0 +1,332 @@
+package terraform
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	hcljson "github.com/hashicorp/hcl/v2/json"
+	"github.com/hashicorp/terraform/configs"
+)
+
+// Verify compares the root modules of the given configurations semantically,
+// the expected one is usually patched in memory,
+// and the actual one is usually loaded from the written files.
+//
+// The terraform settings, resources, data, variables, locals, outputs, providers,
+// module calls, checks, moved, import and removed blocks are compared,
+// including the meta-arguments, regardless of the syntax and the layout of the files.
+//
+// Verify returns an error listing all the differences if any.
+func Verify(expected, actual *Config) error {
+	if expected == nil || actual == nil {
+		if expected == actual {
+			return nil
+		}
+
+		return errors.New("verify: one of the configurations is nil")
+	}
+
+	es, err := summarizeModule(expected.Root.Module)
+	if err != nil {
+		return fmt.Errorf("verify: error summarizing expected configuration: %w", err)
+	}
+
+	as, err := summarizeModule(actual.Root.Module)
+	if err != nil {
+		return fmt.Errorf("verify: error summarizing actual configuration: %w", err)
+	}
+
+	var changes []Change
+	diffValues(&changes, nil, es, as)
+
+	if len(changes) == 0 {
+		return nil
+	}
+
+	diffs := make([]string, 0, len(changes))
+	for _, c := range changes {
+		diffs = append(diffs, c.String())
+	}
+
+	return fmt.Errorf("verify: %d difference(s) found:\n  %s", len(diffs), strings.Join(diffs, "\n  "))
+}
+
+// summarizeModule summarizes the given module into a JSON-like tree,
+// in which the bodies and expressions are encoded as Terraform JSON syntax.
+func summarizeModule(m *configs.Module) (map[string]any, error) {
+	s := map[string]any{}
+
+	// block: terraform
+	{
+		ts := map[string]any{}
+
+		if vcs := coreVersionConstraints(m.CoreVersionConstraints); vcs != "" {
+			ts["required_version"] = vcs
+		}
+
+		if len(m.ActiveExperiments) != 0 {
+			es := make([]any, 0, len(m.ActiveExperiments))
+			for k := range m.ActiveExperiments {
+				es = append(es, k.Keyword())
+			}
+
+			sort.Slice(es, func(i, j int) bool { return es[i].(string) < es[j].(string) })
+			ts["experiments"] = es
+		}
+
+		if m.Backend != nil {
+			b, err := summarizeBody(m.Backend.Config)
+			if err != nil {
+				return nil, fmt.Errorf("backend: %w", err)
+			}
+
+			ts["backend"] = map[string]any{m.Backend.Type: b}
+		}
+
+		if m.CloudConfig != nil {
+			b, err := summarizeBody(m.CloudConfig.Config)
+			if err != nil {
+				return nil, fmt.Errorf("cloud: %w", err)
+			}
+
+			ts["cloud"] = b
+		}
+
+		if m.Encryption != nil {
+			b, err := summarizeBody(m.Encryption.Config)
+			if err != nil {
+				return nil, fmt.Errorf("encryption: %w", err)
+			}
+
+			ts["encryption"] = b
+		}
+
+		if m.ProviderRequirements != nil && len(m.ProviderRequirements.RequiredProviders) != 0 {
+			rps := map[string]any{}
+
+			for n, rp := range m.ProviderRequirements.RequiredProviders {
+				as := make([]any, 0, len(rp.Aliases))
+				for _, a := range rp.Aliases {
+					as = append(as, a.StringCompact())
+				}
+
+				rps[n] = map[string]any{
+					"source":                rp.Source,
+					"version":               rp.Requirement.Required.String(),
+					"configuration_aliases": as,
+				}
+			}
+
+			ts["required_providers"] = rps
+		}
+
+		for p, pm := range m.ProviderMetas {
+			b, err := summarizeBody(pm.Config)
+			if err != nil {
+				return nil, fmt.Errorf("provider_meta %s: %w", pm.Provider, err)
+			}
+
+			ts["provider_meta."+p.String()] = b
+		}
+
+		s["terraform"] = ts
+	}
+
+	// blocks: provider, variable, output, module, resource, data, check.
+	bodies := map[string]map[string]hcl.Body{}
+
+	add := func(typ, name string, body hcl.Body) {
+		if bodies[typ] == nil {
+			bodies[typ] = map[string]hcl.Body{}
+		}
+
+		bodies[typ][name] = body
+	}
+
+	for k, p := range m.ProviderConfigs {
+		add("provider", k, p.Config)
+	}
+
+	for k, v := range m.Variables {
+		add("variable", k, v.Config)
+	}
+
+	for k, o := range m.Outputs {
+		add("output", k, o.Config)
+	}
+
+	for k, mc := range m.ModuleCalls {
+		add("module", k, mc.Config)
+	}
+
+	for k, r := range m.ManagedResources {
+		add("resource", k, r.Config)
+	}
+
+	for k, d := range m.DataResources {
+		add("data", k, d.Config)
+	}
+
+	for k, c := range m.Checks {
+		add("check", k, c.Config)
+	}
+
+	for typ := range bodies {
+		ts := map[string]any{}
+
+		for n, body := range bodies[typ] {
+			b, err := summarizeBody(body)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", typ, n, err)
+			}
+
+			// Keep the scoped data out of the check,
+			// which has been compared as data.
+			if typ == "check" {
+				delete(b, "data")
+			}
+
+			ts[n] = b
+		}
+
+		s[typ] = ts
+	}
+
+	// block: locals
+	if len(m.Locals) != 0 {
+		ls := map[string]any{}
+
+		for n, l := range m.Locals {
+			v, err := summarizeExpression(l.Expr)
+			if err != nil {
+				return nil, fmt.Errorf("local %s: %w", n, err)
+			}
+
+			ls[n] = v
+		}
+
+		s["locals"] = ls
+	}
+
+	// blocks: moved, import, removed.
+	for typ, bs := range map[string][]hcl.Body{
+		"moved":   movedBodies(m.Moved),
+		"import":  importBodies(m.Import),
+		"removed": removedBodies(m.Removed),
+	} {
+		if len(bs) == 0 {
+			continue
+		}
+
+		ts := make([]any, 0, len(bs))
+
+		for i := range bs {
+			b, err := summarizeBody(bs[i], "from", "to")
+			if err != nil {
+				return nil, fmt.Errorf("%s[%d]: %w", typ, i, err)
+			}
+
+			ts = append(ts, b)
+		}
+
+		s[typ] = ts
+	}
+
+	return s, nil
+}
+
+// summarizeBody encodes the given body as a Terraform JSON object, and decodes it into a JSON-like tree,
+// the given static attributes are encoded as literal strings.
+func summarizeBody(body hcl.Body, static ...string) (map[string]any, error) {
+	if body == nil {
+		return map[string]any{}, nil
+	}
+
+	sb, ok := body.(*hclsyntax.Body)
+	if !ok {
+		sb, ok = hcljson.ToSyntaxBody(body)
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("unexpected body type %T", body)
+	}
+
+	if len(static) != 0 {
+		sb = staticAttributes(sb, static...)
+	}
+
+	var buf bytes.Buffer
+	marshalJSONBody(&buf, sb)
+
+	var r map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &r); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// summarizeExpression encodes the given expression as a Terraform JSON value, and decodes it into a JSON-like tree.
+func summarizeExpression(expr hcl.Expression) (any, error) {
+	se, ok := expr.(hclsyntax.Expression)
+	if !ok {
+		se, ok = hcljson.ToSyntaxExpression(expr)
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("unexpected expression type %T", expr)
+	}
+
+	var buf bytes.Buffer
+	marshalJSONExpression(&buf, se)
+
+	var r any
+	if err := json.Unmarshal(buf.Bytes(), &r); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func movedBodies(mvs []*configs.Moved) []hcl.Body {
+	r := make([]hcl.Body, 0, len(mvs))
+	for i := range mvs {
+		r = append(r, mvs[i].Config)
+	}
+
+	return r
+}
+
+func importBodies(ims []*configs.Import) []hcl.Body {
+	r := make([]hcl.Body, 0, len(ims))
+	for i := range ims {
+		r = append(r, ims[i].Config)
+	}
+
+	return r
+}
+
+func removedBodies(rms []*configs.Removed) []hcl.Body {
+	r := make([]hcl.Body, 0, len(rms))
+	for i := range rms {
+		r = append(r, rms[i].Config)
+	}
+
+	return r
+}
+
+// summaryString returns the JSON representation of the given summary value.
+func summaryString(v any) string {
+	bs, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+
+	return string(bs)
+}
@@ -0,0 +1,93 @@
+package terraform
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestVerify writes the configuration of each test case in all syntaxes,
+// and verifies the written configuration against the loaded one.
+//
+// Set TAP_VERIFY_DIR to verify any other directory, e.g.
+// TAP_VERIFY_DIR=/path/to/module go test ./pkg/terraform -run TestVerify.
+func TestVerify(t *testing.T) {
+	testCasesDataDir := filepath.Join("testdata", "load")
+
+	var dirs []string
+
+	if dir := os.Getenv("TAP_VERIFY_DIR"); dir != "" {
+		dirs = append(dirs, dir)
+	} else {
+		testCases, err := os.ReadDir(testCasesDataDir)
+		if err != nil {
+			t.Fatalf("failed to read testdata: %v", err)
+		}
+
+		for _, tc := range testCases {
+			if tc.IsDir() {
+				dirs = append(dirs, filepath.Join(testCasesDataDir, tc.Name()))
+			}
+		}
+	}
+
+	for _, dir := range dirs {
+		flavor := testCaseFlavor(filepath.Base(dir))
+
+		expected, err := Load(dir, WithLoadFlavor(flavor))
+		if !assert.NoErrorf(t, err, "terraform load %s", dir) {
+			continue
+		}
+
+		for _, syntax := range []Syntax{SyntaxHCL, SyntaxJSON} {
+			files, err := WriteFiles(expected, WithFlavor(flavor), WithSyntax(syntax))
+			if !assert.NoErrorf(t, err, "terraform write %s in %s", dir, syntax) {
+				continue
+			}
+
+			writtenDir := t.TempDir()
+
+			for _, f := range files {
+				err = os.WriteFile(filepath.Join(writtenDir, filepath.Base(f.Name)), f.Bytes, 0o600)
+				if err != nil {
+					t.Fatalf("failed to write %s: %v", f.Name, err)
+				}
+			}
+
+			actual, err := Load(writtenDir, WithLoadFlavor(flavor))
+			if !assert.NoErrorf(t, err, "terraform load written %s in %s", dir, syntax) {
+				continue
+			}
+
+			assert.NoErrorf(t, Verify(expected, actual), "verify %s in %s", dir, syntax)
+		}
+	}
+}
+
+func TestVerify_difference(t *testing.T) {
+	dir := filepath.Join("testdata", "load", "with_override")
+
+	expected, err := Load(dir)
+	if err != nil {
+		t.Fatalf("failed to load terraform configuration: %v", err)
+	}
+
+	actual, err := Load(dir)
+	if err != nil {
+		t.Fatalf("failed to load terraform configuration: %v", err)
+	}
+
+	// Lose a resource and a meta-argument.
+	am := actual.Root.Module
+	delete(am.ManagedResources, "kubernetes_secret_v1.secret")
+	delete(am.ManagedResources["kubernetes_config_map_v1.config"].Config.(*hclsyntax.Body).Attributes, "count")
+
+	err = Verify(expected, actual)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "resource.kubernetes_secret_v1.secret: lost")
+		assert.Contains(t, err.Error(), "resource.kubernetes_config_map_v1.config.count: lost, expected 1")
+	}
+}
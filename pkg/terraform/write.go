@@ -1,137 +1,162 @@
 package terraform
 
 import (
+	"fmt"
 	"io"
 	"sort"
+	"strings"
 
+	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/hashicorp/hcl/v2/hclwrite"
 	"github.com/hashicorp/terraform/configs"
 	"github.com/zclconf/go-cty/cty"
 )
 
-// Write writes the given Config to the given writer.
-func Write(cfg *Config, writer io.Writer) error {
-	var (
-		m  = cfg.Root.Module
-		wf = hclwrite.NewFile()
-	)
+// Syntax is the syntax of the written configuration.
+type Syntax string
 
-	// block: terraform
-	{
-		wb := wf.Body()
-		tfBody := wb.AppendNewBlock("terraform", nil).Body()
+const (
+	// SyntaxHCL writes the configuration in HCL native syntax,
+	// keeps the layout of the original files.
+	SyntaxHCL Syntax = "hcl"
+	// SyntaxJSON writes the configuration in Terraform JSON syntax,
+	// into a single main.tf.json file, or main.tofu.json file for FlavorOpenTofu.
+	SyntaxJSON Syntax = "json"
+)
 
-		// attribute: required_version
-		if len(m.CoreVersionConstraints) != 0 && len(m.CoreVersionConstraints[0].Required) != 0 {
-			tfBody.SetAttributeValue("required_version",
-				cty.StringVal(m.CoreVersionConstraints[0].Required.String()))
-			tfBody.AppendNewline()
-		}
+// WriteOptions holds the options of Write.
+type WriteOptions struct {
+	// Flavor is the flavor of the delegated CLI,
+	// if not set, the files are named as FlavorTerraform,
+	// and the blocks only supported by FlavorOpenTofu are written through for the CLI to reject.
+	Flavor Flavor
+	// Syntax is the syntax of the written configuration, defaults to SyntaxHCL.
+	Syntax Syntax
+	// Annotations are the comments to write above the rewritten attributes and blocks.
+	Annotations Annotations
+}
 
-		// attribute: experiments
-		if len(m.ActiveExperiments) != 0 {
-			eBody := make([]cty.Value, 0, len(m.ActiveExperiments))
-			for k := range m.ActiveExperiments {
-				eBody = append(eBody, cty.StringVal(k.Keyword()))
-			}
+// Annotations holds the comment lines of the rewritten attributes and blocks,
+// indexed by the address of the owner object,
+// e.g. "aws_instance.web", "data.aws_ami.ubuntu", "var.name", "output.id" or "locals",
+// then by the name of the top-level attribute or the type of the top-level block,
+// or the name of the local for "locals".
+type Annotations map[string]map[string][]string
 
-			tfBody.SetAttributeValue("experiments", cty.ListVal(eBody))
-			tfBody.AppendNewline()
-		}
+// WriteOption configures the WriteOptions.
+type WriteOption func(*WriteOptions)
 
-		switch {
-		case m.Backend != nil:
-			// block: backend
-			bgBody := tfBody.AppendNewBlock("backend", []string{m.Backend.Type}).Body()
-			bgBody.AppendHCLBody(m.Backend.Config)
-			tfBody.AppendNewline()
-		case m.CloudConfig != nil:
-			// block: cloud
-			ccBody := tfBody.AppendNewBlock("cloud", nil).Body()
-			ccBody.AppendHCLBody(m.CloudConfig.Config)
-			tfBody.AppendNewline()
-		}
+// WithFlavor configures the flavor of the delegated CLI.
+func WithFlavor(f Flavor) WriteOption {
+	return func(o *WriteOptions) {
+		o.Flavor = f
+	}
+}
 
-		// block: required_providers
-		if m.ProviderRequirements != nil && len(m.ProviderRequirements.RequiredProviders) != 0 {
-			requiredProviders := make([]*configs.RequiredProvider, 0, len(m.ProviderRequirements.RequiredProviders))
-			for k := range m.ProviderRequirements.RequiredProviders {
-				requiredProviders = append(requiredProviders, m.ProviderRequirements.RequiredProviders[k])
-			}
+// WithSyntax configures the syntax of the written configuration.
+func WithSyntax(s Syntax) WriteOption {
+	return func(o *WriteOptions) {
+		o.Syntax = s
+	}
+}
 
-			sort.Slice(requiredProviders, func(i, j int) bool {
-				if requiredProviders[i].DeclRange.Filename == requiredProviders[j].DeclRange.Filename {
-					return requiredProviders[i].DeclRange.Start.Line < requiredProviders[j].DeclRange.Start.Line
-				}
+// WithAnnotations configures the comments to write above the rewritten attributes and blocks,
+// which are only written in HCL native syntax.
+func WithAnnotations(a Annotations) WriteOption {
+	return func(o *WriteOptions) {
+		o.Annotations = a
+	}
+}
 
-				return requiredProviders[i].DeclRange.Filename < requiredProviders[j].DeclRange.Filename
-			})
+// Write writes the given Config to the given writer,
+// the written files are concatenated in order.
+func Write(cfg *Config, writer io.Writer, opts ...WriteOption) error {
+	files, err := WriteFiles(cfg, opts...)
+	if err != nil {
+		return err
+	}
 
-			rpBody := tfBody.AppendNewBlock("required_providers", nil).Body()
+	for i := range files {
+		if i != 0 {
+			if _, err = writer.Write([]byte{'\n'}); err != nil {
+				return err
+			}
+		}
 
-			for i, rp := range requiredProviders {
-				rpCtyValMap := map[string]cty.Value{}
-				if rp.Source != "" {
-					rpCtyValMap["source"] = cty.StringVal(rp.Source)
-				}
+		if _, err = writer.Write(files[i].Bytes); err != nil {
+			return err
+		}
+	}
 
-				if rp.Requirement.Required.String() != "" {
-					rpCtyValMap["version"] = cty.StringVal(rp.Requirement.Required.String())
-				}
+	return nil
+}
 
-				if len(rpCtyValMap) == 0 {
-					continue
-				}
+// WriteFiles writes the given Config into files,
+// each file is named after the original primary file of the root module.
+//
+// Each file is written back from its original source,
+// in which only the attributes and blocks changed by patching are rewritten,
+// so that the comments and formatting are preserved as much as possible.
+// The override files are merged away.
+//
+// With SyntaxJSON, the whole configuration is written into a single main.tf.json file instead,
+// in which the expressions are encoded as "${...}" template strings.
+//
+// With FlavorOpenTofu, the generated files are named with the .tofu and .tofu.json extensions,
+// while with FlavorTerraform, the encryption block only supported by OpenTofu is rejected.
+//
+// With Annotations, the comments are written above the rewritten attributes and blocks of the HCL native files,
+// the comments of the removed attributes and blocks are written above the owner block instead.
+func WriteFiles(cfg *Config, opts ...WriteOption) ([]File, error) {
+	o := WriteOptions{
+		Syntax: SyntaxHCL,
+	}
 
-				rpBody.SetAttributeValue(rp.Name, cty.ObjectVal(rpCtyValMap))
+	for i := range opts {
+		opts[i](&o)
+	}
 
-				if i != len(requiredProviders)-1 {
-					rpBody.AppendNewline()
-				}
-			}
-		}
+	m := cfg.Root.Module
 
-		// block: provider_meta
-		if len(m.ProviderMetas) != 0 {
-			for _, pm := range m.ProviderMetas {
-				pmBody := tfBody.AppendNewBlock("provider_meta", []string{pm.Provider}).Body()
-				pmBody.AppendHCLBody(pm.Config)
-			}
+	if m.Encryption != nil && o.Flavor == FlavorTerraform {
+		return nil, fmt.Errorf("encryption block at %s is only supported by OpenTofu", m.Encryption.DeclRange)
+	}
 
-			tfBody.AppendNewline()
+	switch o.Syntax {
+	case SyntaxHCL:
+		return writeSourceFiles(m, o.Flavor, o.Annotations)
+	case SyntaxJSON:
+		bs, err := writeJSONModule(m)
+		if err != nil {
+			return nil, err
 		}
 
-		wb.AppendNewline()
+		return []File{{Name: "main" + fileExt(o.Flavor) + ".json", Bytes: bs}}, nil
 	}
 
+	return nil, fmt.Errorf("unknown syntax %q", o.Syntax)
+}
+
+// writeModule writes the blocks of the given module into the given hclwrite.Body in structure,
+// only the blocks declared in the range accepted by the given filter are written,
+// except the terraform block which is written by writeTerraform.
+func writeModule(wb *hclwrite.Body, m *configs.Module, in func(hcl.Range) bool) {
 	// block: provider
 	{
 		providers := make([]*configs.Provider, 0, len(m.ProviderConfigs))
 		for k := range m.ProviderConfigs {
-			providers = append(providers, m.ProviderConfigs[k])
+			if in(m.ProviderConfigs[k].DeclRange) {
+				providers = append(providers, m.ProviderConfigs[k])
+			}
 		}
 
 		sort.Slice(providers, func(i, j int) bool {
-			if providers[i].DeclRange.Filename == providers[j].DeclRange.Filename {
-				return providers[i].DeclRange.Start.Line < providers[j].DeclRange.Start.Line
-			}
-
-			return providers[i].DeclRange.Filename < providers[j].DeclRange.Filename
+			return lessDeclRange(providers[i].DeclRange, providers[j].DeclRange)
 		})
 
-		wb := wf.Body()
 		for _, p := range providers {
-			pBody := wb.AppendNewBlock("provider", []string{p.Name}).Body()
-			if p.Alias != "" {
-				pBody.SetAttributeValue("alias", cty.StringVal(p.Alias))
-			}
-
-			if len(p.Version.Required) != 0 {
-				pBody.SetAttributeValue("version", cty.StringVal(p.Version.Required.String()))
-			}
-
-			pBody.AppendHCLBody(p.Config)
+			writeProvider(wb.AppendNewBlock("provider", []string{p.Name}).Body(), p)
 			wb.AppendNewline()
 		}
 	}
@@ -140,30 +165,17 @@ func Write(cfg *Config, writer io.Writer) error {
 	{
 		variables := make([]*configs.Variable, 0, len(m.Variables))
 		for k := range m.Variables {
-			variables = append(variables, m.Variables[k])
+			if in(m.Variables[k].DeclRange) {
+				variables = append(variables, m.Variables[k])
+			}
 		}
 
 		sort.Slice(variables, func(i, j int) bool {
-			if variables[i].DeclRange.Filename == variables[j].DeclRange.Filename {
-				return variables[i].DeclRange.Start.Line < variables[j].DeclRange.Start.Line
-			}
-
-			return variables[i].DeclRange.Filename < variables[j].DeclRange.Filename
+			return lessDeclRange(variables[i].DeclRange, variables[j].DeclRange)
 		})
 
-		wb := wf.Body()
 		for _, v := range variables {
-			wb.AppendUnstructuredTokens(hclwrite.Tokens{
-				{
-					Type:  hclsyntax.TokenIdent,
-					Bytes: []byte("variable"),
-				},
-				{
-					Type:  hclsyntax.TokenIdent,
-					Bytes: []byte(`"` + v.Name + `"`),
-				},
-			})
-			wb.AppendUnstructuredTokens(fromHCLTokens(v.Tokens, false))
+			writeVariable(wb, v)
 			wb.AppendNewline()
 		}
 	}
@@ -172,25 +184,17 @@ func Write(cfg *Config, writer io.Writer) error {
 	{
 		locals := make([]*configs.Local, 0, len(m.Locals))
 		for k := range m.Locals {
-			locals = append(locals, m.Locals[k])
+			if in(m.Locals[k].DeclRange) {
+				locals = append(locals, m.Locals[k])
+			}
 		}
 
 		sort.Slice(locals, func(i, j int) bool {
-			if locals[i].DeclRange.Filename == locals[j].DeclRange.Filename {
-				return locals[i].DeclRange.Start.Line < locals[j].DeclRange.Start.Line
-			}
-
-			return locals[i].DeclRange.Filename < locals[j].DeclRange.Filename
+			return lessDeclRange(locals[i].DeclRange, locals[j].DeclRange)
 		})
 
 		if len(locals) != 0 {
-			wb := wf.Body()
-
-			lsBody := wb.AppendNewBlock("locals", nil).Body()
-			for _, l := range locals {
-				lsBody.SetAttributeRaw(l.Name, fromHCLTokens(l.Tokens, true))
-			}
-
+			writeLocals(wb.AppendNewBlock("locals", nil).Body(), locals)
 			wb.AppendNewline()
 		}
 	}
@@ -199,28 +203,17 @@ func Write(cfg *Config, writer io.Writer) error {
 	{
 		resources := make([]*configs.Resource, 0, len(m.ManagedResources))
 		for k := range m.ManagedResources {
-			resources = append(resources, m.ManagedResources[k])
+			if in(m.ManagedResources[k].DeclRange) {
+				resources = append(resources, m.ManagedResources[k])
+			}
 		}
 
 		sort.Slice(resources, func(i, j int) bool {
-			if resources[i].DeclRange.Filename == resources[j].DeclRange.Filename {
-				return resources[i].DeclRange.Start.Line < resources[j].DeclRange.Start.Line
-			}
-
-			return resources[i].DeclRange.Filename < resources[j].DeclRange.Filename
+			return lessDeclRange(resources[i].DeclRange, resources[j].DeclRange)
 		})
 
-		wb := wf.Body()
 		for _, r := range resources {
-			rBody := wb.AppendNewBlock("resource", []string{r.Type, r.Name}).Body()
-
-			// attribute: provider
-			if r.ProviderConfigRef != nil {
-				rBody.SetAttributeValue("provider", cty.StringVal(r.ProviderConfigRef.Name))
-				rBody.AppendNewline()
-			}
-
-			rBody.AppendHCLBody(r.Config)
+			writeResource(wb.AppendNewBlock("resource", []string{r.Type, r.Name}).Body(), r)
 			wb.AppendNewline()
 		}
 	}
@@ -229,28 +222,22 @@ func Write(cfg *Config, writer io.Writer) error {
 	{
 		datas := make([]*configs.Resource, 0, len(m.DataResources))
 		for k := range m.DataResources {
-			datas = append(datas, m.DataResources[k])
-		}
+			// Skip the scoped data of check blocks.
+			if m.DataResources[k].Container != nil {
+				continue
+			}
 
-		sort.Slice(datas, func(i, j int) bool {
-			if datas[i].DeclRange.Filename == datas[j].DeclRange.Filename {
-				return datas[i].DeclRange.Start.Line < datas[j].DeclRange.Start.Line
+			if in(m.DataResources[k].DeclRange) {
+				datas = append(datas, m.DataResources[k])
 			}
+		}
 
-			return datas[i].DeclRange.Filename < datas[j].DeclRange.Filename
+		sort.Slice(datas, func(i, j int) bool {
+			return lessDeclRange(datas[i].DeclRange, datas[j].DeclRange)
 		})
 
-		wb := wf.Body()
 		for _, d := range datas {
-			dBody := wb.AppendNewBlock("data", []string{d.Type, d.Name}).Body()
-
-			// attribute: provider
-			if d.ProviderConfigRef != nil {
-				dBody.SetAttributeValue("provider", cty.StringVal(d.ProviderConfigRef.Name))
-				dBody.AppendNewline()
-			}
-
-			dBody.AppendHCLBody(d.Config)
+			writeResource(wb.AppendNewBlock("data", []string{d.Type, d.Name}).Body(), d)
 			wb.AppendNewline()
 		}
 	}
@@ -259,34 +246,60 @@ func Write(cfg *Config, writer io.Writer) error {
 	{
 		modules := make([]*configs.ModuleCall, 0, len(m.ModuleCalls))
 		for k := range m.ModuleCalls {
-			modules = append(modules, m.ModuleCalls[k])
+			if in(m.ModuleCalls[k].DeclRange) {
+				modules = append(modules, m.ModuleCalls[k])
+			}
 		}
 
 		sort.Slice(modules, func(i, j int) bool {
-			if modules[i].DeclRange.Filename == modules[j].DeclRange.Filename {
-				return modules[i].DeclRange.Start.Line < modules[j].DeclRange.Start.Line
+			return lessDeclRange(modules[i].DeclRange, modules[j].DeclRange)
+		})
+
+		for _, mc := range modules {
+			writeModuleCall(wb.AppendNewBlock("module", []string{mc.Name}).Body(), mc)
+			wb.AppendNewline()
+		}
+	}
+
+	// block: check
+	{
+		checks := make([]*configs.Check, 0, len(m.Checks))
+		for k := range m.Checks {
+			if in(m.Checks[k].DeclRange) {
+				checks = append(checks, m.Checks[k])
 			}
+		}
 
-			return modules[i].DeclRange.Filename < modules[j].DeclRange.Filename
+		sort.Slice(checks, func(i, j int) bool {
+			return lessDeclRange(checks[i].DeclRange, checks[j].DeclRange)
 		})
 
-		wb := wf.Body()
-		for _, m := range modules {
-			mBody := wb.AppendNewBlock("module", []string{m.Name}).Body()
+		for _, c := range checks {
+			writeCheck(wb.AppendNewBlock("check", []string{c.Name}).Body(), c)
+			wb.AppendNewline()
+		}
+	}
 
-			// attribute: source
-			if m.SourceSet {
-				mBody.SetAttributeValue("source", cty.StringVal(m.SourceAddrRaw))
-				mBody.AppendNewline()
-			}
+	// block: moved
+	for _, mv := range m.Moved {
+		if in(mv.DeclRange) {
+			wb.AppendNewBlock("moved", nil).Body().AppendHCLBody(mv.Config)
+			wb.AppendNewline()
+		}
+	}
 
-			// attribute: version
-			if len(m.Version.Required) != 0 {
-				mBody.SetAttributeValue("required_version", cty.StringVal(m.Version.Required.String()))
-				mBody.AppendNewline()
-			}
+	// block: import
+	for _, im := range m.Import {
+		if in(im.DeclRange) {
+			wb.AppendNewBlock("import", nil).Body().AppendHCLBody(im.Config)
+			wb.AppendNewline()
+		}
+	}
 
-			mBody.AppendHCLBody(m.Config)
+	// block: removed
+	for _, rm := range m.Removed {
+		if in(rm.DeclRange) {
+			wb.AppendNewBlock("removed", nil).Body().AppendHCLBody(rm.Config)
 			wb.AppendNewline()
 		}
 	}
@@ -295,38 +308,413 @@ func Write(cfg *Config, writer io.Writer) error {
 	{
 		outputs := make([]*configs.Output, 0, len(m.Outputs))
 		for k := range m.Outputs {
-			outputs = append(outputs, m.Outputs[k])
+			if in(m.Outputs[k].DeclRange) {
+				outputs = append(outputs, m.Outputs[k])
+			}
 		}
 
 		sort.Slice(outputs, func(i, j int) bool {
-			if outputs[i].DeclRange.Filename == outputs[j].DeclRange.Filename {
-				return outputs[i].DeclRange.Start.Line < outputs[j].DeclRange.Start.Line
-			}
+			return lessDeclRange(outputs[i].DeclRange, outputs[j].DeclRange)
+		})
+
+		for _, o := range outputs {
+			writeOutput(wb, o)
+			wb.AppendNewline()
+		}
+	}
+}
+
+// writeTerraform writes the merged terraform settings of the given module into the given hclwrite.Body.
+func writeTerraform(tfBody *hclwrite.Body, m *configs.Module) {
+	// attribute: required_version
+	if vcs := coreVersionConstraints(m.CoreVersionConstraints); vcs != "" {
+		tfBody.SetAttributeValue("required_version", cty.StringVal(vcs))
+	}
+
+	// attribute: experiments
+	if len(m.ActiveExperiments) != 0 {
+		es := make([]string, 0, len(m.ActiveExperiments))
+		for k := range m.ActiveExperiments {
+			es = append(es, k.Keyword())
+		}
+
+		sort.Strings(es)
+
+		appendSeparator(tfBody)
+		tfBody.SetAttributeRaw("experiments", tokensForIdentifiers(es))
+	}
+
+	switch {
+	case m.Backend != nil:
+		// block: backend
+		appendSeparator(tfBody)
+		bgBody := tfBody.AppendNewBlock("backend", []string{m.Backend.Type}).Body()
+		bgBody.AppendHCLBody(m.Backend.Config)
+	case m.CloudConfig != nil:
+		// block: cloud
+		appendSeparator(tfBody)
+		ccBody := tfBody.AppendNewBlock("cloud", nil).Body()
+		ccBody.AppendHCLBody(m.CloudConfig.Config)
+	}
+
+	// block: encryption
+	if m.Encryption != nil {
+		appendSeparator(tfBody)
+		tfBody.AppendNewBlock("encryption", nil).Body().AppendHCLBody(m.Encryption.Config)
+	}
 
-			return outputs[i].DeclRange.Filename < outputs[j].DeclRange.Filename
+	// block: required_providers
+	if m.ProviderRequirements != nil && len(m.ProviderRequirements.RequiredProviders) != 0 {
+		requiredProviders := make([]*configs.RequiredProvider, 0, len(m.ProviderRequirements.RequiredProviders))
+		for k := range m.ProviderRequirements.RequiredProviders {
+			requiredProviders = append(requiredProviders, m.ProviderRequirements.RequiredProviders[k])
+		}
+
+		sort.Slice(requiredProviders, func(i, j int) bool {
+			return lessDeclRange(requiredProviders[i].DeclRange, requiredProviders[j].DeclRange)
 		})
 
-		wb := wf.Body()
-		for i, o := range outputs {
-			wb.AppendUnstructuredTokens(hclwrite.Tokens{
-				{
-					Type:  hclsyntax.TokenIdent,
-					Bytes: []byte("output"),
-				},
-				{
-					Type:  hclsyntax.TokenIdent,
-					Bytes: []byte(`"` + o.Name + `"`),
-				},
-			})
-			wb.AppendUnstructuredTokens(fromHCLTokens(o.Tokens, false))
-
-			if i != len(outputs)-1 {
-				wb.AppendNewline()
+		appendSeparator(tfBody)
+		rpBody := tfBody.AppendNewBlock("required_providers", nil).Body()
+
+		for _, rp := range requiredProviders {
+			rpAttrs := make([]hclwrite.ObjectAttrTokens, 0, 3)
+			if rp.Source != "" {
+				rpAttrs = append(rpAttrs, hclwrite.ObjectAttrTokens{
+					Name:  hclwrite.TokensForIdentifier("source"),
+					Value: hclwrite.TokensForValue(cty.StringVal(rp.Source)),
+				})
+			}
+
+			if rp.Requirement.Required.String() != "" {
+				rpAttrs = append(rpAttrs, hclwrite.ObjectAttrTokens{
+					Name:  hclwrite.TokensForIdentifier("version"),
+					Value: hclwrite.TokensForValue(cty.StringVal(rp.Requirement.Required.String())),
+				})
+			}
+
+			if len(rp.Aliases) != 0 {
+				rpAttrs = append(rpAttrs, hclwrite.ObjectAttrTokens{
+					Name:  hclwrite.TokensForIdentifier("configuration_aliases"),
+					Value: tokensForLocalProviderConfigs(rp.Aliases),
+				})
 			}
+
+			if len(rpAttrs) == 0 {
+				continue
+			}
+
+			appendSeparator(rpBody)
+			rpBody.SetAttributeRaw(rp.Name, hclwrite.TokensForObject(rpAttrs))
 		}
 	}
 
-	_, err := wf.WriteTo(writer)
+	// block: provider_meta
+	if len(m.ProviderMetas) != 0 {
+		appendSeparator(tfBody)
+
+		for _, pm := range m.ProviderMetas {
+			pmBody := tfBody.AppendNewBlock("provider_meta", []string{pm.Provider}).Body()
+			pmBody.AppendHCLBody(pm.Config)
+		}
+	}
+}
+
+// appendSeparator appends a blank line into the given hclwrite.Body,
+// if there are attributes or blocks written before,
+// so that the items are separated without a blank line before the closing brace.
+func appendSeparator(b *hclwrite.Body) {
+	if len(b.Attributes()) != 0 || len(b.Blocks()) != 0 {
+		b.AppendNewline()
+	}
+}
+
+// writeProvider writes the given provider into the given hclwrite.Body.
+func writeProvider(pBody *hclwrite.Body, p *configs.Provider) {
+	// attribute: alias
+	if p.Alias != "" {
+		pBody.SetAttributeValue("alias", cty.StringVal(p.Alias))
+	}
+
+	// attribute: version
+	if len(p.Version.Required) != 0 {
+		pBody.SetAttributeValue("version", cty.StringVal(p.Version.Required.String()))
+	}
+
+	appendBody(pBody, p.Config)
+}
+
+// writeVariable appends the given variable block into the given hclwrite.Body.
+func writeVariable(wb *hclwrite.Body, v *configs.Variable) {
+	// Write the patched variable in structure.
+	if v.Tokens == nil {
+		wb.AppendNewBlock("variable", []string{v.Name}).Body().AppendHCLBody(v.Config)
+		return
+	}
+
+	wb.AppendUnstructuredTokens(hclwrite.Tokens{
+		{
+			Type:  hclsyntax.TokenIdent,
+			Bytes: []byte("variable"),
+		},
+		{
+			Type:  hclsyntax.TokenIdent,
+			Bytes: []byte(`"` + v.Name + `"`),
+		},
+	})
+	wb.AppendUnstructuredTokens(fromHCLTokens(v.Tokens, false))
+}
+
+// writeLocals writes the given locals into the given hclwrite.Body of a locals block.
+func writeLocals(lsBody *hclwrite.Body, locals []*configs.Local) {
+	for _, l := range locals {
+		lsBody.SetAttributeRaw(l.Name, tokensForLocal(l))
+	}
+}
+
+// writeCheck writes the given check into the given hclwrite.Body,
+// includes the scoped data.
+func writeCheck(cBody *hclwrite.Body, c *configs.Check) {
+	// block: data
+	if d := c.DataResource; d != nil {
+		writeResource(cBody.AppendNewBlock("data", []string{d.Type, d.Name}).Body(), d)
+		cBody.AppendNewline()
+	}
+
+	// block: assert
+	for _, cr := range c.Asserts {
+		writeCheckRule(cBody.AppendNewBlock("assert", nil).Body(), cr)
+	}
+}
+
+// writeOutput appends the given output block into the given hclwrite.Body.
+func writeOutput(wb *hclwrite.Body, o *configs.Output) {
+	// Write the patched output in structure.
+	if o.Tokens == nil {
+		wb.AppendNewBlock("output", []string{o.Name}).Body().AppendHCLBody(o.Config)
+		return
+	}
+
+	wb.AppendUnstructuredTokens(hclwrite.Tokens{
+		{
+			Type:  hclsyntax.TokenIdent,
+			Bytes: []byte("output"),
+		},
+		{
+			Type:  hclsyntax.TokenIdent,
+			Bytes: []byte(`"` + o.Name + `"`),
+		},
+	})
+	wb.AppendUnstructuredTokens(fromHCLTokens(o.Tokens, false))
+}
+
+// writeResource writes the given resource or data into the given hclwrite.Body,
+// includes the meta-arguments.
+func writeResource(rBody *hclwrite.Body, r *configs.Resource) {
+	// attribute: count
+	if r.Count != nil {
+		rBody.SetAttributeRaw("count", tokensForExpression(r.Count))
+	}
+
+	// attribute: for_each
+	if r.ForEach != nil {
+		rBody.SetAttributeRaw("for_each", tokensForExpression(r.ForEach))
+	}
+
+	// attribute: provider
+	if r.ProviderConfigRef != nil {
+		rBody.SetAttributeRaw("provider", tokensForProviderConfigRef(r.ProviderConfigRef))
+	}
+
+	if r.Count != nil || r.ForEach != nil || r.ProviderConfigRef != nil {
+		rBody.AppendNewline()
+	}
+
+	appendBody(rBody, r.Config)
+
+	// attribute: depends_on
+	if len(r.DependsOn) != 0 {
+		rBody.SetAttributeRaw("depends_on", tokensForTraversals(r.DependsOn))
+	}
+
+	// block: lifecycle
+	{
+		var (
+			mr      = r.Managed
+			lcBody  *hclwrite.Body
+			lcBlock = func() *hclwrite.Body {
+				if lcBody == nil {
+					lcBody = rBody.AppendNewBlock("lifecycle", nil).Body()
+				}
+
+				return lcBody
+			}
+		)
+
+		if mr != nil {
+			// attribute: create_before_destroy
+			if mr.CreateBeforeDestroySet {
+				lcBlock().SetAttributeValue("create_before_destroy", cty.BoolVal(mr.CreateBeforeDestroy))
+			}
+
+			// attribute: prevent_destroy
+			if mr.PreventDestroySet {
+				lcBlock().SetAttributeValue("prevent_destroy", cty.BoolVal(mr.PreventDestroy))
+			}
+
+			// attribute: ignore_changes
+			switch {
+			case mr.IgnoreAllChanges:
+				lcBlock().SetAttributeRaw("ignore_changes", hclwrite.TokensForIdentifier("all"))
+			case len(mr.IgnoreChanges) != 0:
+				lcBlock().SetAttributeRaw("ignore_changes", tokensForTraversals(mr.IgnoreChanges))
+			}
+		}
+
+		// attribute: replace_triggered_by
+		if len(r.TriggersReplacement) != 0 {
+			lcBlock().SetAttributeRaw("replace_triggered_by", tokensForExpressions(r.TriggersReplacement))
+		}
+
+		// block: precondition
+		for _, cr := range r.Preconditions {
+			writeCheckRule(lcBlock().AppendNewBlock("precondition", nil).Body(), cr)
+		}
+
+		// block: postcondition
+		for _, cr := range r.Postconditions {
+			writeCheckRule(lcBlock().AppendNewBlock("postcondition", nil).Body(), cr)
+		}
+	}
+
+	if r.Managed == nil {
+		return
+	}
+
+	// block: connection
+	if c := r.Managed.Connection; c != nil {
+		rBody.AppendNewBlock("connection", nil).Body().AppendHCLBody(c.Config)
+	}
+
+	// block: provisioner
+	for _, pv := range r.Managed.Provisioners {
+		pvBody := rBody.AppendNewBlock("provisioner", []string{pv.Type}).Body()
+
+		// attribute: when
+		if pv.When == configs.ProvisionerWhenDestroy {
+			pvBody.SetAttributeRaw("when", hclwrite.TokensForIdentifier("destroy"))
+		}
+
+		// attribute: on_failure
+		if pv.OnFailure == configs.ProvisionerOnFailureContinue {
+			pvBody.SetAttributeRaw("on_failure", hclwrite.TokensForIdentifier("continue"))
+		}
+
+		appendBody(pvBody, pv.Config)
+
+		// block: connection
+		if c := pv.Connection; c != nil {
+			pvBody.AppendNewBlock("connection", nil).Body().AppendHCLBody(c.Config)
+		}
+	}
+}
+
+// writeModuleCall writes the given module call into the given hclwrite.Body,
+// includes the meta-arguments.
+func writeModuleCall(mBody *hclwrite.Body, mc *configs.ModuleCall) {
+	// attribute: source
+	if mc.SourceSet {
+		mBody.SetAttributeValue("source", cty.StringVal(mc.SourceAddrRaw))
+	}
+
+	// attribute: version
+	if len(mc.Version.Required) != 0 {
+		mBody.SetAttributeValue("version", cty.StringVal(mc.Version.Required.String()))
+	}
+
+	// attribute: count
+	if mc.Count != nil {
+		mBody.SetAttributeRaw("count", tokensForExpression(mc.Count))
+	}
+
+	// attribute: for_each
+	if mc.ForEach != nil {
+		mBody.SetAttributeRaw("for_each", tokensForExpression(mc.ForEach))
+	}
+
+	// attribute: providers
+	if len(mc.Providers) != 0 {
+		mBody.SetAttributeRaw("providers", tokensForPassedProviderConfigs(mc.Providers))
+	}
+
+	mBody.AppendNewline()
+
+	appendBody(mBody, mc.Config)
+
+	// attribute: depends_on
+	if len(mc.DependsOn) != 0 {
+		mBody.SetAttributeRaw("depends_on", tokensForTraversals(mc.DependsOn))
+	}
+}
+
+// writeCheckRule writes the given precondition or postcondition into the given hclwrite.Body.
+func writeCheckRule(crBody *hclwrite.Body, cr *configs.CheckRule) {
+	crBody.SetAttributeRaw("condition", tokensForExpression(cr.Condition))
+	crBody.SetAttributeRaw("error_message", tokensForExpression(cr.ErrorMessage))
+}
+
+// appendBody is similar to hclwrite.Body's AppendHCLBody,
+// but skips the attributes and blocks hidden by the previous decoding,
+// which are usually the meta-arguments.
+func appendBody(wb *hclwrite.Body, body hcl.Body) {
+	bd, ok := body.(*hclsyntax.Body)
+	if !ok {
+		return
+	}
+
+	vbd := &hclsyntax.Body{
+		Attributes: make(hclsyntax.Attributes, len(bd.Attributes)),
+		Blocks:     make(hclsyntax.Blocks, 0, len(bd.Blocks)),
+	}
+
+	for n := range bd.Attributes {
+		if bd.IsHiddenAttribute(n) {
+			continue
+		}
+
+		vbd.Attributes[n] = bd.Attributes[n]
+	}
+
+	for i := range bd.Blocks {
+		if bd.IsHiddenBlock(bd.Blocks[i].Type) {
+			continue
+		}
+
+		vbd.Blocks = append(vbd.Blocks, bd.Blocks[i])
+	}
+
+	wb.AppendHCLBody(vbd)
+}
+
+// coreVersionConstraints joins all the given version constraints,
+// which may come from different files.
+func coreVersionConstraints(vcs []configs.VersionConstraint) string {
+	var ss []string
+
+	for i := range vcs {
+		for _, c := range vcs[i].Required {
+			ss = append(ss, strings.TrimSpace(c.String()))
+		}
+	}
+
+	return strings.Join(ss, ", ")
+}
+
+// lessDeclRange returns true if the given range a is declared before the given range b.
+func lessDeclRange(a, b hcl.Range) bool {
+	if a.Filename == b.Filename {
+		return a.Start.Byte < b.Start.Byte
+	}
 
-	return err
+	return a.Filename < b.Filename
 }
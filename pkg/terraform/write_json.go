@@ -0,0 +1,883 @@
+package terraform
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	hcljson "github.com/hashicorp/hcl/v2/json"
+	"github.com/hashicorp/terraform/configs"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+	"golang.org/x/exp/slices"
+)
+
+// ToJSONBody converts the given hclsyntax.Body back into a JSON body with the given filename,
+// the body is usually converted from a JSON body during loading,
+// so that it can be decoded with a schema again.
+func ToJSONBody(body *hclsyntax.Body, filename string) (hcl.Body, error) {
+	var buf bytes.Buffer
+	marshalJSONBody(&buf, body)
+
+	f, diags := hcljson.Parse(buf.Bytes(), filename)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	return f.Body, nil
+}
+
+// marshalJSONBody writes the given hclsyntax.Body as a JSON object in compact,
+// attributes go first in the original order, then the blocks grouped by type.
+func marshalJSONBody(buf *bytes.Buffer, body *hclsyntax.Body) {
+	attrs := make([]*hclsyntax.Attribute, 0, len(body.Attributes))
+	for n := range body.Attributes {
+		attrs = append(attrs, body.Attributes[n])
+	}
+
+	// Keep the attributes declared in the same file as the body in the original order,
+	// and put the others after them by name.
+	sort.Slice(attrs, func(i, j int) bool {
+		ii := attrs[i].SrcRange.Filename == body.SrcRange.Filename
+		ij := attrs[j].SrcRange.Filename == body.SrcRange.Filename
+
+		switch {
+		case ii && ij:
+			return attrs[i].SrcRange.Start.Byte < attrs[j].SrcRange.Start.Byte
+		case ii != ij:
+			return ii
+		}
+
+		return attrs[i].Name < attrs[j].Name
+	})
+
+	buf.WriteByte('{')
+
+	for i := range attrs {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		marshalJSONString(buf, attrs[i].Name)
+		buf.WriteByte(':')
+		marshalJSONMember(buf, attrs[i].Name, attrs[i].Expr)
+	}
+
+	var (
+		typs []string
+		blks = make(map[string][]*hclsyntax.Block)
+	)
+
+	for _, blk := range body.Blocks {
+		if _, exist := blks[blk.Type]; !exist {
+			typs = append(typs, blk.Type)
+		}
+
+		blks[blk.Type] = append(blks[blk.Type], blk)
+	}
+
+	for i, typ := range typs {
+		if i > 0 || len(attrs) > 0 {
+			buf.WriteByte(',')
+		}
+
+		marshalJSONString(buf, typ)
+		buf.WriteByte(':')
+		buf.WriteByte('[')
+
+		for j, blk := range blks[typ] {
+			if j > 0 {
+				buf.WriteByte(',')
+			}
+
+			// Nest the body in the labels.
+			for _, l := range blk.Labels {
+				buf.WriteByte('{')
+				marshalJSONString(buf, l)
+				buf.WriteByte(':')
+			}
+
+			marshalJSONBody(buf, blk.Body)
+
+			for range blk.Labels {
+				buf.WriteByte('}')
+			}
+		}
+
+		buf.WriteByte(']')
+	}
+
+	buf.WriteByte('}')
+}
+
+// marshalJSONExpression writes the given hclsyntax.Expression as a JSON value,
+// literals and constructors are written as they are,
+// the others are written as string templates in interpolation.
+func marshalJSONExpression(buf *bytes.Buffer, expr hclsyntax.Expression) {
+	switch e := expr.(type) {
+	case *hclsyntax.LiteralValueExpr:
+		marshalJSONValue(buf, e.Val)
+	case *hclsyntax.TemplateExpr:
+		var sb strings.Builder
+
+		for _, p := range e.Parts {
+			if l, ok := p.(*hclsyntax.LiteralValueExpr); ok && l.Val.Type() == cty.String && l.Val.IsKnown() && !l.Val.IsNull() {
+				sb.WriteString(escapeJSONTemplate(l.Val.AsString()))
+				continue
+			}
+
+			sb.WriteString("${")
+			sb.WriteString(expressionSource(p))
+			sb.WriteString("}")
+		}
+
+		marshalJSONString(buf, sb.String())
+	case *hclsyntax.TemplateWrapExpr:
+		marshalJSONString(buf, "${"+expressionSource(e.Wrapped)+"}")
+	case *hclsyntax.TupleConsExpr:
+		buf.WriteByte('[')
+
+		for i := range e.Exprs {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+
+			marshalJSONExpression(buf, e.Exprs[i])
+		}
+
+		buf.WriteByte(']')
+	case *hclsyntax.ObjectConsExpr:
+		buf.WriteByte('{')
+
+		for i := range e.Items {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+
+			k := marshalJSONObjectKey(buf, e.Items[i].KeyExpr)
+			buf.WriteByte(':')
+			marshalJSONMember(buf, k, e.Items[i].ValueExpr)
+		}
+
+		buf.WriteByte('}')
+	default:
+		marshalJSONString(buf, "${"+expressionSource(expr)+"}")
+	}
+}
+
+// marshalJSONObjectKey writes the given key expression of an object constructor as a JSON string,
+// returns the static key name if possible.
+func marshalJSONObjectKey(buf *bytes.Buffer, expr hclsyntax.Expression) string {
+	if k, ok := expr.(*hclsyntax.ObjectConsKeyExpr); ok {
+		if !k.ForceNonLiteral {
+			if n := hcl.ExprAsKeyword(k.Wrapped); n != "" {
+				marshalJSONString(buf, n)
+				return n
+			}
+		}
+
+		expr = k.Wrapped
+	}
+
+	switch e := expr.(type) {
+	case *hclsyntax.LiteralValueExpr:
+		marshalJSONExpression(buf, e)
+
+		if e.Val.Type() == cty.String && e.Val.IsKnown() && !e.Val.IsNull() {
+			return e.Val.AsString()
+		}
+	case *hclsyntax.TemplateExpr, *hclsyntax.TemplateWrapExpr:
+		marshalJSONExpression(buf, e)
+	default:
+		marshalJSONString(buf, "${"+expressionSource(expr)+"}")
+	}
+
+	return ""
+}
+
+// jsonStaticMembers holds the names of the members,
+// whose values are static references or type constraints rather than templates in JSON syntax.
+var jsonStaticMembers = map[string]bool{
+	"configuration_aliases": true,
+	"depends_on":            true,
+	"experiments":           true,
+	"ignore_changes":        true,
+	"on_failure":            true,
+	"replace_triggered_by":  true,
+	"provider":              true,
+	"providers":             true,
+	"type":                  true,
+	"when":                  true,
+}
+
+// marshalJSONMember writes the value of the named member as a JSON value.
+func marshalJSONMember(buf *bytes.Buffer, name string, expr hclsyntax.Expression) {
+	if !jsonStaticMembers[name] {
+		marshalJSONExpression(buf, expr)
+		return
+	}
+
+	marshalJSONStaticExpression(buf, expr)
+}
+
+// marshalJSONStaticExpression writes the given hclsyntax.Expression as a JSON value,
+// the references and type constraints are written as plain strings.
+func marshalJSONStaticExpression(buf *bytes.Buffer, expr hclsyntax.Expression) {
+	switch e := expr.(type) {
+	case *hclsyntax.ObjectConsKeyExpr:
+		marshalJSONStaticExpression(buf, e.Wrapped)
+	case *hclsyntax.LiteralValueExpr:
+		marshalJSONValue(buf, e.Val)
+	case *hclsyntax.TemplateExpr:
+		if !e.IsStringLiteral() {
+			marshalJSONString(buf, expressionSource(expr))
+			break
+		}
+
+		// Quoted literals, e.g. type = "ssh", are written without the quotes.
+		v, _ := e.Value(nil)
+		marshalJSONValue(buf, v)
+	case *hclsyntax.TupleConsExpr:
+		buf.WriteByte('[')
+
+		for i := range e.Exprs {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+
+			marshalJSONStaticExpression(buf, e.Exprs[i])
+		}
+
+		buf.WriteByte(']')
+	case *hclsyntax.ObjectConsExpr:
+		buf.WriteByte('{')
+
+		for i := range e.Items {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+
+			marshalJSONStaticExpression(buf, e.Items[i].KeyExpr)
+			buf.WriteByte(':')
+			marshalJSONStaticExpression(buf, e.Items[i].ValueExpr)
+		}
+
+		buf.WriteByte('}')
+	default:
+		marshalJSONString(buf, expressionSource(expr))
+	}
+}
+
+// marshalJSONValue writes the given cty.Value as a JSON value,
+// strings are escaped to avoid being interpreted as templates.
+func marshalJSONValue(buf *bytes.Buffer, val cty.Value) {
+	switch {
+	case !val.IsKnown() || val.IsNull():
+		buf.WriteString("null")
+	case val.Type() == cty.String:
+		marshalJSONString(buf, escapeJSONTemplate(val.AsString()))
+	case val.Type() == cty.Number:
+		buf.WriteString(val.AsBigFloat().Text('f', -1))
+	case val.Type() == cty.Bool:
+		if val.True() {
+			buf.WriteString("true")
+		} else {
+			buf.WriteString("false")
+		}
+	default:
+		bs, err := ctyjson.Marshal(val, val.Type())
+		if err != nil {
+			buf.WriteString("null")
+			return
+		}
+
+		buf.Write(bs)
+	}
+}
+
+func marshalJSONString(buf *bytes.Buffer, s string) {
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+
+	// Drop the trailing newline added by the encoder.
+	buf.Truncate(buf.Len() - 1)
+}
+
+// escapeJSONTemplate escapes the template sequences of the given literal string.
+func escapeJSONTemplate(s string) string {
+	s = strings.ReplaceAll(s, "${", "$${")
+	s = strings.ReplaceAll(s, "%{", "%%{")
+
+	return s
+}
+
+// expressionSource returns the formatted HCL native source of the given hclsyntax.Expression.
+func expressionSource(expr hclsyntax.Expression) string {
+	wf := hclwrite.NewEmptyFile()
+	wf.Body().SetAttributeRaw("x", hclwrite.TokensForExpression(expr))
+
+	s := strings.TrimSpace(string(wf.Bytes()))
+	s = strings.TrimSpace(strings.TrimPrefix(s, "x"))
+
+	return strings.TrimSpace(strings.TrimPrefix(s, "="))
+}
+
+// indentJSON indents the given compact JSON,
+// the lines except the first are prefixed with the given prefix.
+func indentJSON(bs []byte, prefix string) []byte {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, bs, prefix, "  "); err != nil {
+		return bs
+	}
+
+	return buf.Bytes()
+}
+
+// jsonEdit replaces the bytes between Start and End with the Bytes.
+type jsonEdit struct {
+	Start, End int
+	Bytes      []byte
+}
+
+// applyJSONEdits applies the given non-overlapping edits to the given source.
+func applyJSONEdits(src []byte, edits []jsonEdit) []byte {
+	sort.Slice(edits, func(i, j int) bool {
+		return edits[i].Start > edits[j].Start
+	})
+
+	r := append([]byte{}, src...)
+	for _, e := range edits {
+		r = append(r[:e.Start], append(append([]byte{}, e.Bytes...), r[e.End:]...)...)
+	}
+
+	return r
+}
+
+// jsonRemoval returns the edit to remove the member between the given offsets,
+// including the adjacent comma.
+func jsonRemoval(src []byte, start, end int) jsonEdit {
+	j := end
+	for j < len(src) && isJSONSpace(src[j]) {
+		j++
+	}
+
+	if j < len(src) && src[j] == ',' {
+		// Remove the following comma and the spaces before the next member.
+		j++
+		for j < len(src) && isJSONSpace(src[j]) {
+			j++
+		}
+
+		return jsonEdit{Start: start, End: j}
+	}
+
+	i := start - 1
+	for i >= 0 && isJSONSpace(src[i]) {
+		i--
+	}
+
+	if i >= 0 && src[i] == ',' {
+		return jsonEdit{Start: i, End: end}
+	}
+
+	return jsonEdit{Start: start, End: end}
+}
+
+// jsonTopLevelMembers returns the offsets of the top-level members with the given name,
+// each offset pair covers from the member name to the end of the member value.
+func jsonTopLevelMembers(src []byte, name string) ([][2]int, error) {
+	dec := json.NewDecoder(bytes.NewReader(src))
+
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return nil, fmt.Errorf("want JSON object")
+	}
+
+	var r [][2]int
+
+	for dec.More() {
+		// Locate the start of the member name.
+		start := int(dec.InputOffset())
+		for start < len(src) && (isJSONSpace(src[start]) || src[start] == ',') {
+			start++
+		}
+
+		t, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		var v json.RawMessage
+		if err = dec.Decode(&v); err != nil {
+			return nil, err
+		}
+
+		if t == name {
+			r = append(r, [2]int{start, int(dec.InputOffset())})
+		}
+	}
+
+	return r, nil
+}
+
+// appendJSONBlock appends the top-level blocks of the given HCL native source
+// into the top-level object of the given JSON source.
+func appendJSONBlock(src, hclSrc []byte) ([]byte, error) {
+	f, diags := hclsyntax.ParseConfig(hclSrc, "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	for _, blk := range f.Body.(*hclsyntax.Body).Blocks {
+		var buf bytes.Buffer
+		marshalJSONBody(&buf, blk.Body)
+
+		src = applyJSONEdits(src, []jsonEdit{jsonAppendMember(src, blk.Type, buf.Bytes())})
+	}
+
+	return src, nil
+}
+
+// jsonAppendMember returns the edit to append a member with the given name and compact value
+// into the top-level object of the given source.
+func jsonAppendMember(src []byte, name string, value []byte) jsonEdit {
+	end := bytes.LastIndexByte(src, '}')
+	if end < 0 {
+		end = len(src)
+	}
+
+	i := end - 1
+	for i >= 0 && isJSONSpace(src[i]) {
+		i--
+	}
+
+	var buf bytes.Buffer
+	if i >= 0 && src[i] != '{' {
+		buf.WriteByte(',')
+	}
+
+	buf.WriteString("\n  ")
+	marshalJSONString(&buf, name)
+	buf.WriteString(": ")
+	buf.Write(indentJSON(value, "  "))
+	buf.WriteByte('\n')
+
+	return jsonEdit{Start: i + 1, End: end, Bytes: buf.Bytes()}
+}
+
+// lineIndent returns the leading spaces of the line where the given offset is.
+func lineIndent(src []byte, offset int) string {
+	i := bytes.LastIndexByte(src[:offset], '\n') + 1
+
+	j := i
+	for j < offset && (src[j] == ' ' || src[j] == '\t') {
+		j++
+	}
+
+	return string(src[i:j])
+}
+
+func isJSONSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// writeJSONModule writes the given module in Terraform JSON syntax.
+//
+// The objects declared in JSON syntax are written from their bodies,
+// the others are rendered in HCL native syntax first.
+func writeJSONModule(m *configs.Module) ([]byte, error) {
+	var root jsonNode
+
+	add := func(path []string, body *hclsyntax.Body) {
+		var buf bytes.Buffer
+		marshalJSONBody(&buf, body)
+		root.add(path, buf.Bytes())
+	}
+
+	// block: terraform
+	{
+		tb, err := renderSyntaxBody(func(wb *hclwrite.Body) {
+			writeTerraform(wb.AppendNewBlock("terraform", nil).Body(), m)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error rendering terraform block: %w", err)
+		}
+
+		if len(tb.Attributes) != 0 || len(tb.Blocks) != 0 {
+			add([]string{"terraform"}, tb)
+		}
+	}
+
+	// block: provider
+	{
+		providers := make([]*configs.Provider, 0, len(m.ProviderConfigs))
+		for k := range m.ProviderConfigs {
+			providers = append(providers, m.ProviderConfigs[k])
+		}
+
+		sort.Slice(providers, func(i, j int) bool {
+			return lessDeclRange(providers[i].DeclRange, providers[j].DeclRange)
+		})
+
+		for _, p := range providers {
+			b, err := jsonSyntaxBody(p.DeclRange, p.Config, providerWriter(p))
+			if err != nil {
+				return nil, fmt.Errorf("error rendering provider %s: %w", p.Addr().StringCompact(), err)
+			}
+
+			add([]string{"provider", p.Name}, b)
+		}
+	}
+
+	// block: variable
+	{
+		variables := make([]*configs.Variable, 0, len(m.Variables))
+		for k := range m.Variables {
+			variables = append(variables, m.Variables[k])
+		}
+
+		sort.Slice(variables, func(i, j int) bool {
+			return lessDeclRange(variables[i].DeclRange, variables[j].DeclRange)
+		})
+
+		for _, v := range variables {
+			b, err := jsonSyntaxBody(v.DeclRange, v.Config, func(wb *hclwrite.Body) { writeVariable(wb, v) })
+			if err != nil {
+				return nil, fmt.Errorf("error rendering variable %s: %w", v.Name, err)
+			}
+
+			add([]string{"variable", v.Name}, b)
+		}
+	}
+
+	// block: locals
+	{
+		locals := make([]*configs.Local, 0, len(m.Locals))
+		for k := range m.Locals {
+			locals = append(locals, m.Locals[k])
+		}
+
+		sort.Slice(locals, func(i, j int) bool {
+			return lessDeclRange(locals[i].DeclRange, locals[j].DeclRange)
+		})
+
+		for _, l := range locals {
+			e, ok := l.Expr.(hclsyntax.Expression)
+			if !ok {
+				return nil, fmt.Errorf("error rendering local %s: unexpected expression type %T", l.Name, l.Expr)
+			}
+
+			var buf bytes.Buffer
+			marshalJSONExpression(&buf, e)
+			root.add([]string{"locals", l.Name}, buf.Bytes())
+		}
+	}
+
+	// block: resource, data
+	for _, typ := range []string{"resource", "data"} {
+		ress := m.ManagedResources
+		if typ == "data" {
+			ress = m.DataResources
+		}
+
+		resources := make([]*configs.Resource, 0, len(ress))
+		for k := range ress {
+			// Skip the scoped data of check blocks.
+			if ress[k].Container != nil {
+				continue
+			}
+
+			resources = append(resources, ress[k])
+		}
+
+		sort.Slice(resources, func(i, j int) bool {
+			return lessDeclRange(resources[i].DeclRange, resources[j].DeclRange)
+		})
+
+		for _, r := range resources {
+			b, err := jsonSyntaxBody(r.DeclRange, r.Config, resourceWriter(typ, r))
+			if err != nil {
+				return nil, fmt.Errorf("error rendering %s: %w", r.Addr(), err)
+			}
+
+			add([]string{typ, r.Type, r.Name}, b)
+		}
+	}
+
+	// block: module
+	{
+		modules := make([]*configs.ModuleCall, 0, len(m.ModuleCalls))
+		for k := range m.ModuleCalls {
+			modules = append(modules, m.ModuleCalls[k])
+		}
+
+		sort.Slice(modules, func(i, j int) bool {
+			return lessDeclRange(modules[i].DeclRange, modules[j].DeclRange)
+		})
+
+		for _, mc := range modules {
+			b, err := jsonSyntaxBody(mc.DeclRange, mc.Config, moduleCallWriter(mc))
+			if err != nil {
+				return nil, fmt.Errorf("error rendering module %s: %w", mc.Name, err)
+			}
+
+			add([]string{"module", mc.Name}, b)
+		}
+	}
+
+	// block: check
+	{
+		checks := make([]*configs.Check, 0, len(m.Checks))
+		for k := range m.Checks {
+			checks = append(checks, m.Checks[k])
+		}
+
+		sort.Slice(checks, func(i, j int) bool {
+			return lessDeclRange(checks[i].DeclRange, checks[j].DeclRange)
+		})
+
+		for _, c := range checks {
+			b, err := jsonSyntaxCheckBody(c)
+			if err != nil {
+				return nil, fmt.Errorf("error rendering check %s: %w", c.Name, err)
+			}
+
+			add([]string{"check", c.Name}, b)
+		}
+	}
+
+	// block: moved, import, removed
+	{
+		type refactoring struct {
+			typ       string
+			body      hcl.Body
+			declRange hcl.Range
+			statics   []string
+		}
+
+		var rs []refactoring
+
+		for _, mv := range m.Moved {
+			rs = append(rs, refactoring{"moved", mv.Config, mv.DeclRange, []string{"from", "to"}})
+		}
+
+		for _, im := range m.Import {
+			rs = append(rs, refactoring{"import", im.Config, im.DeclRange, []string{"to"}})
+		}
+
+		for _, rm := range m.Removed {
+			rs = append(rs, refactoring{"removed", rm.Config, rm.DeclRange, []string{"from"}})
+		}
+
+		for _, r := range rs {
+			b, ok := r.body.(*hclsyntax.Body)
+			if !ok {
+				if b, ok = hcljson.ToSyntaxBody(r.body); !ok {
+					return nil, fmt.Errorf("error rendering %s block at %s: unexpected body type %T", r.typ, r.declRange, r.body)
+				}
+			}
+
+			add([]string{r.typ}, staticAttributes(b, r.statics...))
+		}
+	}
+
+	// block: output
+	{
+		outputs := make([]*configs.Output, 0, len(m.Outputs))
+		for k := range m.Outputs {
+			outputs = append(outputs, m.Outputs[k])
+		}
+
+		sort.Slice(outputs, func(i, j int) bool {
+			return lessDeclRange(outputs[i].DeclRange, outputs[j].DeclRange)
+		})
+
+		for _, o := range outputs {
+			b, err := jsonSyntaxBody(o.DeclRange, o.Config, func(wb *hclwrite.Body) { writeOutput(wb, o) })
+			if err != nil {
+				return nil, fmt.Errorf("error rendering output %s: %w", o.Name, err)
+			}
+
+			add([]string{"output", o.Name}, b)
+		}
+	}
+
+	var buf bytes.Buffer
+	root.marshal(&buf)
+
+	return append(indentJSON(buf.Bytes(), ""), '\n'), nil
+}
+
+// jsonSyntaxBody returns the body of the object to write in JSON syntax,
+// the body of the object declared in JSON syntax is returned as it is,
+// otherwise, the body is rendered by the given blockWriter.
+func jsonSyntaxBody(declRange hcl.Range, body hcl.Body, w blockWriter) (*hclsyntax.Body, error) {
+	if !isNativeSyntax(declRange.Filename) {
+		if sb, ok := body.(*hclsyntax.Body); ok {
+			return sb, nil
+		}
+
+		if sb, ok := hcljson.ToSyntaxBody(body); ok {
+			return sb, nil
+		}
+	}
+
+	return renderSyntaxBody(w)
+}
+
+// jsonSyntaxCheckBody returns the body of the given check to write in JSON syntax,
+// includes the current scoped data.
+func jsonSyntaxCheckBody(c *configs.Check) (*hclsyntax.Body, error) {
+	if isNativeSyntax(c.DeclRange.Filename) {
+		return renderSyntaxBody(checkWriter(c))
+	}
+
+	cb, ok := hcljson.ToSyntaxBody(c.Config)
+	if !ok {
+		return nil, fmt.Errorf("unexpected body type %T", c.Config)
+	}
+
+	if c.DataResource == nil {
+		return cb, nil
+	}
+
+	// Replace the original scoped data with the current one.
+	d := c.DataResource
+
+	db, err := jsonSyntaxBody(d.DeclRange, d.Config, resourceWriter("data", d))
+	if err != nil {
+		return nil, err
+	}
+
+	b := &hclsyntax.Body{
+		Attributes: make(hclsyntax.Attributes, len(cb.Attributes)),
+		Blocks: hclsyntax.Blocks{
+			{
+				Type:   "data",
+				Labels: []string{d.Type, d.Name},
+				Body:   db,
+			},
+		},
+		SrcRange: cb.SrcRange,
+		EndRange: cb.EndRange,
+	}
+
+	for n := range cb.Attributes {
+		if n != "data" {
+			b.Attributes[n] = cb.Attributes[n]
+		}
+	}
+
+	return b, nil
+}
+
+// renderSyntaxBody renders the block written by the given blockWriter,
+// returns the body parsed in HCL native syntax.
+func renderSyntaxBody(w blockWriter) (*hclsyntax.Body, error) {
+	wf := hclwrite.NewEmptyFile()
+	w(wf.Body())
+
+	f, diags := hclsyntax.ParseConfig(wf.Bytes(), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	blks := f.Body.(*hclsyntax.Body).Blocks
+	if len(blks) == 0 {
+		return &hclsyntax.Body{Attributes: hclsyntax.Attributes{}}, nil
+	}
+
+	return blks[0].Body, nil
+}
+
+// staticAttributes returns a copy of the given hclsyntax.Body,
+// in which the named attributes are replaced with their source as literal strings.
+func staticAttributes(body *hclsyntax.Body, names ...string) *hclsyntax.Body {
+	r := *body
+	r.Attributes = make(hclsyntax.Attributes, len(body.Attributes))
+
+	for n, attr := range body.Attributes {
+		r.Attributes[n] = attr
+
+		if _, ok := attr.Expr.(*hclsyntax.LiteralValueExpr); ok || !slices.Contains(names, n) {
+			continue
+		}
+
+		a := *attr
+		a.Expr = &hclsyntax.LiteralValueExpr{
+			Val:      cty.StringVal(expressionSource(attr.Expr)),
+			SrcRange: attr.Expr.Range(),
+		}
+		r.Attributes[n] = &a
+	}
+
+	return &r
+}
+
+// jsonNode is a JSON object in construction,
+// whose members are kept in the added order.
+type jsonNode struct {
+	keys     []string
+	children map[string]*jsonNode
+	values   [][]byte
+}
+
+// add adds the given compact JSON value under the given path,
+// the values added under the same path are written as an array.
+func (n *jsonNode) add(path []string, value []byte) {
+	if len(path) == 0 {
+		n.values = append(n.values, value)
+		return
+	}
+
+	if n.children == nil {
+		n.children = make(map[string]*jsonNode)
+	}
+
+	c, exist := n.children[path[0]]
+	if !exist {
+		c = &jsonNode{}
+		n.children[path[0]] = c
+		n.keys = append(n.keys, path[0])
+	}
+
+	c.add(path[1:], value)
+}
+
+func (n *jsonNode) marshal(buf *bytes.Buffer) {
+	switch {
+	case len(n.values) == 1:
+		buf.Write(n.values[0])
+	case len(n.values) > 1:
+		buf.WriteByte('[')
+
+		for i := range n.values {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+
+			buf.Write(n.values[i])
+		}
+
+		buf.WriteByte(']')
+	default:
+		buf.WriteByte('{')
+
+		for i, k := range n.keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+
+			marshalJSONString(buf, k)
+			buf.WriteByte(':')
+			n.children[k].marshal(buf)
+		}
+
+		buf.WriteByte('}')
+	}
+}
@@ -0,0 +1,745 @@
+package terraform
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	hcljson "github.com/hashicorp/hcl/v2/json"
+	"github.com/hashicorp/terraform/configs"
+	"github.com/spf13/afero"
+	"golang.org/x/exp/slices"
+)
+
+// File holds the written content of a primary file of the root module.
+type File struct {
+	// Name is the name of the original file.
+	Name string
+	// Bytes is the written content.
+	Bytes []byte
+}
+
+// writeSourceFiles writes back the primary files of the given module.
+//
+// The files are written from their original source,
+// only the attributes and blocks of HCL native files,
+// or the objects of JSON files that differ from the original are rewritten.
+// The override files are not written, since they have been merged into the module.
+//
+// The given annotations are written above the rewritten attributes and blocks of HCL native files.
+func writeSourceFiles(m *configs.Module, flavor Flavor, notes Annotations) ([]File, error) {
+	// Write in structure if the module is not loaded from files.
+	if len(m.SourceFiles) == 0 {
+		wf := hclwrite.NewEmptyFile()
+		writeTerraform(wf.Body().AppendNewBlock("terraform", nil).Body(), m)
+		wf.Body().AppendNewline()
+		writeModule(wf.Body(), m, func(hcl.Range) bool { return true })
+
+		return []File{{Name: "main" + fileExt(flavor), Bytes: trimBytes(wf.Bytes())}}, nil
+	}
+
+	// Decode the original files.
+	origs, err := loadSourceFiles(m.SourceFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	// Regenerate the terraform block if its settings are also declared in the override files,
+	// because the override files are not written back.
+	var regenTerraform bool
+
+	for _, sf := range m.SourceFiles {
+		if sf.Override && hasTerraformSettings(origs[sf.Name]) {
+			regenTerraform = true
+			break
+		}
+	}
+
+	var (
+		names       []string
+		wfs         = make(map[string]*hclwrite.File)
+		jfs         = make(map[string][]byte)
+		seenLocals  = make(map[string]struct{})
+		firstLocals *hclwrite.Body
+		firstNative string
+	)
+
+	for _, sf := range m.SourceFiles {
+		if sf.Override {
+			continue
+		}
+
+		names = append(names, sf.Name)
+
+		if !isNativeSyntax(sf.Name) {
+			bs, err := patchJSONSourceFile(sf.Bytes, m, origs[sf.Name], regenTerraform, seenLocals)
+			if err != nil {
+				return nil, fmt.Errorf("error patching %s: %w", sf.Name, err)
+			}
+
+			jfs[sf.Name] = bs
+
+			continue
+		}
+
+		if firstNative == "" {
+			firstNative = sf.Name
+		}
+
+		wf, diags := hclwrite.ParseConfig(sf.Bytes, sf.Name, hcl.InitialPos)
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("error parsing %s: %w", sf.Name, diags)
+		}
+
+		lsBody := patchSourceFile(wf.Body(), sf.Name, m, origs[sf.Name], regenTerraform, seenLocals, notes)
+		if firstLocals == nil {
+			firstLocals = lsBody
+		}
+
+		wfs[sf.Name] = wf
+	}
+
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	// Write the added locals into the first locals block,
+	// or a new locals block of the first HCL native file,
+	// or a new locals object of the first JSON file.
+	{
+		var added []*configs.Local
+
+		for n := range m.Locals {
+			if _, seen := seenLocals[n]; !seen {
+				added = append(added, m.Locals[n])
+			}
+		}
+
+		sort.Slice(added, func(i, j int) bool {
+			return added[i].Name < added[j].Name
+		})
+
+		switch {
+		case len(added) == 0:
+		case firstLocals != nil:
+			writeLocals(firstLocals, added)
+			annotateLocals(firstLocals, added, notes["locals"])
+		case firstNative != "":
+			wb := wfs[firstNative].Body()
+			wb.AppendNewline()
+
+			lsBody := wb.AppendNewBlock("locals", nil).Body()
+			writeLocals(lsBody, added)
+			annotateLocals(lsBody, added, notes["locals"])
+		default:
+			wf := hclwrite.NewEmptyFile()
+			writeLocals(wf.Body().AppendNewBlock("locals", nil).Body(), added)
+
+			jfs[names[0]], err = appendJSONBlock(jfs[names[0]], wf.Bytes())
+			if err != nil {
+				return nil, fmt.Errorf("error writing locals: %w", err)
+			}
+		}
+	}
+
+	files := make([]File, 0, len(names))
+
+	for _, n := range names {
+		if bs, ok := jfs[n]; ok {
+			files = append(files, File{Name: n, Bytes: bs})
+			continue
+		}
+
+		files = append(files, File{Name: n, Bytes: trimBytes(wfs[n].Bytes())})
+	}
+
+	// Write the regenerated terraform block into the first HCL native file,
+	// or the first JSON file.
+	if regenTerraform {
+		wf := hclwrite.NewEmptyFile()
+		writeTerraform(wf.Body().AppendNewBlock("terraform", nil).Body(), m)
+
+		i := slices.Index(names, firstNative)
+		if i >= 0 {
+			files[i].Bytes = append(append(wf.Bytes(), '\n'), files[i].Bytes...)
+		} else {
+			files[0].Bytes, err = appendJSONBlock(files[0].Bytes, wf.Bytes())
+			if err != nil {
+				return nil, fmt.Errorf("error writing terraform block: %w", err)
+			}
+		}
+	}
+
+	return files, nil
+}
+
+// loadSourceFiles decodes the given source files independently,
+// returns the decoded files indexed by the filename.
+func loadSourceFiles(sfs []configs.SourceFile) (map[string]*configs.File, error) {
+	fs := afero.Afero{Fs: afero.NewMemMapFs()}
+
+	for _, sf := range sfs {
+		if err := fs.WriteFile(sf.Name, sf.Bytes, 0o600); err != nil {
+			return nil, fmt.Errorf("error caching %s: %w", sf.Name, err)
+		}
+	}
+
+	parser := configs.NewParser(fs)
+	parser.AllowLanguageExperiments(true)
+
+	r := make(map[string]*configs.File, len(sfs))
+
+	for _, sf := range sfs {
+		var (
+			f     *configs.File
+			diags hcl.Diagnostics
+		)
+
+		if sf.Override {
+			f, diags = parser.LoadConfigFileOverride(sf.Name)
+		} else {
+			f, diags = parser.LoadConfigFile(sf.Name)
+		}
+
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("error decoding %s: %w", sf.Name, diags)
+		}
+
+		r[sf.Name] = f
+	}
+
+	return r, nil
+}
+
+// patchSourceFile patches the top-level blocks of the given hclwrite.Body parsed from the original file,
+// which is compared between the original decoded file and the given module,
+// returns the body of the first locals block if found.
+//
+// The given annotations are written above the rewritten attributes and blocks.
+func patchSourceFile(
+	wb *hclwrite.Body,
+	filename string,
+	m *configs.Module,
+	orig *configs.File,
+	regenTerraform bool,
+	seenLocals map[string]struct{},
+	notes Annotations,
+) (firstLocals *hclwrite.Body) {
+	indexes := make(map[string]int)
+
+	for _, blk := range wb.Blocks() {
+		switch typ := blk.Type(); typ {
+		case "terraform":
+			if regenTerraform {
+				wb.RemoveBlock(blk)
+			}
+		case "locals":
+			patchLocals(blk.Body(), filename, m, seenLocals, notes["locals"])
+
+			if firstLocals == nil {
+				firstLocals = blk.Body()
+			}
+		default:
+			i := indexes[typ]
+			indexes[typ]++
+
+			ow, cw, addr := pairBlockWriters(typ, i, m, orig)
+			if ow == nil || cw == nil {
+				continue
+			}
+
+			ob, obs := renderBlock(ow)
+			cb, cbs := renderBlock(cw)
+
+			if ob == nil || cb == nil || bytes.Equal(obs, cbs) {
+				continue
+			}
+
+			patchBody(blk.Body(), ob.Body(), cb.Body(), notes[addr])
+
+			// Annotate the owner block with the notes of the removed attributes and blocks.
+			for _, h := range sortedKeys(notes[addr]) {
+				if !hasHead(cb.Body(), h) {
+					blk.AppendLeadComments(notes[addr][h]...)
+				}
+			}
+		}
+	}
+
+	return firstLocals
+}
+
+// patchJSONSourceFile patches the objects of the given JSON source,
+// which is compared between the original decoded file and the given module,
+// returns the patched source.
+func patchJSONSourceFile(
+	src []byte,
+	m *configs.Module,
+	orig *configs.File,
+	regenTerraform bool,
+	seenLocals map[string]struct{},
+) ([]byte, error) {
+	var edits []jsonEdit
+
+	// Replace the changed objects.
+	replace := func(ob, cb hcl.Body) {
+		rng, ok := hcljson.BodyRange(ob)
+		if !ok {
+			return
+		}
+
+		osb, ok := hcljson.ToSyntaxBody(ob)
+		if !ok {
+			return
+		}
+
+		csb, ok := cb.(*hclsyntax.Body)
+		if !ok {
+			return
+		}
+
+		var obs, cbs bytes.Buffer
+		marshalJSONBody(&obs, osb)
+		marshalJSONBody(&cbs, csb)
+
+		if bytes.Equal(obs.Bytes(), cbs.Bytes()) {
+			return
+		}
+
+		edits = append(edits, jsonEdit{
+			Start: rng.Start.Byte,
+			End:   rng.End.Byte,
+			Bytes: indentJSON(cbs.Bytes(), lineIndent(src, rng.Start.Byte)),
+		})
+	}
+
+	for _, op := range orig.ProviderConfigs {
+		if cp := m.ProviderConfigs[op.Addr().StringCompact()]; cp != nil {
+			replace(op.Config, cp.Config)
+		}
+	}
+
+	for _, ov := range orig.Variables {
+		if cv := m.Variables[ov.Name]; cv != nil {
+			replace(ov.Config, cv.Config)
+		}
+	}
+
+	for _, oo := range orig.Outputs {
+		if co := m.Outputs[oo.Name]; co != nil {
+			replace(oo.Config, co.Config)
+		}
+	}
+
+	for _, omc := range orig.ModuleCalls {
+		if cmc := m.ModuleCalls[omc.Name]; cmc != nil {
+			replace(omc.Config, cmc.Config)
+		}
+	}
+
+	for _, or := range orig.ManagedResources {
+		if cr := m.ManagedResources[or.Addr().String()]; cr != nil {
+			replace(or.Config, cr.Config)
+		}
+	}
+
+	oress := orig.DataResources
+	for _, oc := range orig.Checks {
+		if oc.DataResource != nil {
+			oress = append(oress, oc.DataResource)
+		}
+	}
+
+	for _, or := range oress {
+		if cr := m.DataResources[or.Addr().String()]; cr != nil {
+			replace(or.Config, cr.Config)
+		}
+	}
+
+	// Patch the locals.
+	for _, ol := range orig.Locals {
+		seenLocals[ol.Name] = struct{}{}
+
+		cl, exist := m.Locals[ol.Name]
+		if !exist {
+			edits = append(edits, jsonRemoval(src, ol.DeclRange.Start.Byte, ol.DeclRange.End.Byte))
+			continue
+		}
+
+		oe, ok := hcljson.ToSyntaxExpression(ol.Expr)
+		if !ok {
+			continue
+		}
+
+		ce, ok := cl.Expr.(hclsyntax.Expression)
+		if !ok {
+			continue
+		}
+
+		var oes, ces bytes.Buffer
+		marshalJSONExpression(&oes, oe)
+		marshalJSONExpression(&ces, ce)
+
+		if bytes.Equal(oes.Bytes(), ces.Bytes()) {
+			continue
+		}
+
+		rng := ol.Expr.Range()
+		edits = append(edits, jsonEdit{
+			Start: rng.Start.Byte,
+			End:   rng.End.Byte,
+			Bytes: indentJSON(ces.Bytes(), lineIndent(src, rng.Start.Byte)),
+		})
+	}
+
+	src = applyJSONEdits(src, edits)
+
+	// Remove the terraform objects, which are regenerated.
+	if regenTerraform {
+		mrs, err := jsonTopLevelMembers(src, "terraform")
+		if err != nil {
+			return nil, err
+		}
+
+		edits = edits[:0]
+		for _, mr := range mrs {
+			edits = append(edits, jsonRemoval(src, mr[0], mr[1]))
+		}
+
+		src = applyJSONEdits(src, edits)
+	}
+
+	return src, nil
+}
+
+// blockWriter appends a block into the given hclwrite.Body.
+type blockWriter func(wb *hclwrite.Body)
+
+// pairBlockWriters returns the writers of the i-th block with the given type,
+// one writes the original block, another writes the current block,
+// and the address of the block, returns nil if the block should be kept as it is.
+func pairBlockWriters(typ string, i int, m *configs.Module, orig *configs.File) (blockWriter, blockWriter, string) {
+	switch typ {
+	case "provider":
+		if i >= len(orig.ProviderConfigs) {
+			return nil, nil, ""
+		}
+
+		op := orig.ProviderConfigs[i]
+
+		cp := m.ProviderConfigs[op.Addr().StringCompact()]
+		if cp == nil {
+			return nil, nil, ""
+		}
+
+		return providerWriter(op), providerWriter(cp), "provider." + op.Addr().StringCompact()
+	case "variable":
+		if i >= len(orig.Variables) {
+			return nil, nil, ""
+		}
+
+		ov := orig.Variables[i]
+
+		cv := m.Variables[ov.Name]
+		if cv == nil {
+			return nil, nil, ""
+		}
+
+		return func(wb *hclwrite.Body) { writeVariable(wb, ov) },
+			func(wb *hclwrite.Body) { writeVariable(wb, cv) },
+			cv.Addr().String()
+	case "output":
+		if i >= len(orig.Outputs) {
+			return nil, nil, ""
+		}
+
+		oo := orig.Outputs[i]
+
+		co := m.Outputs[oo.Name]
+		if co == nil {
+			return nil, nil, ""
+		}
+
+		return func(wb *hclwrite.Body) { writeOutput(wb, oo) },
+			func(wb *hclwrite.Body) { writeOutput(wb, co) },
+			co.Addr().String()
+	case "resource", "data":
+		ress, oress := m.ManagedResources, orig.ManagedResources
+		if typ == "data" {
+			ress, oress = m.DataResources, orig.DataResources
+		}
+
+		if i >= len(oress) {
+			return nil, nil, ""
+		}
+
+		or := oress[i]
+
+		cr := ress[or.Addr().String()]
+		if cr == nil {
+			return nil, nil, ""
+		}
+
+		return resourceWriter(typ, or), resourceWriter(typ, cr), cr.Addr().String()
+	case "module":
+		if i >= len(orig.ModuleCalls) {
+			return nil, nil, ""
+		}
+
+		omc := orig.ModuleCalls[i]
+
+		cmc := m.ModuleCalls[omc.Name]
+		if cmc == nil {
+			return nil, nil, ""
+		}
+
+		return moduleCallWriter(omc), moduleCallWriter(cmc), "module." + cmc.Name
+	case "check":
+		if i >= len(orig.Checks) {
+			return nil, nil, ""
+		}
+
+		oc := orig.Checks[i]
+
+		cc := m.Checks[oc.Name]
+		if cc == nil {
+			return nil, nil, ""
+		}
+
+		return checkWriter(oc), checkWriter(cc), "check." + cc.Name
+	}
+
+	// Keep the moved, import and removed blocks,
+	// which cannot be patched or overridden.
+	return nil, nil, ""
+}
+
+func providerWriter(p *configs.Provider) blockWriter {
+	return func(wb *hclwrite.Body) {
+		writeProvider(wb.AppendNewBlock("provider", []string{p.Name}).Body(), p)
+	}
+}
+
+func resourceWriter(typ string, r *configs.Resource) blockWriter {
+	return func(wb *hclwrite.Body) {
+		writeResource(wb.AppendNewBlock(typ, []string{r.Type, r.Name}).Body(), r)
+	}
+}
+
+func moduleCallWriter(mc *configs.ModuleCall) blockWriter {
+	return func(wb *hclwrite.Body) {
+		writeModuleCall(wb.AppendNewBlock("module", []string{mc.Name}).Body(), mc)
+	}
+}
+
+func checkWriter(c *configs.Check) blockWriter {
+	return func(wb *hclwrite.Body) {
+		writeCheck(wb.AppendNewBlock("check", []string{c.Name}).Body(), c)
+	}
+}
+
+// renderBlock renders the block written by the given blockWriter,
+// returns the block parsed from the formatted output and the formatted output.
+func renderBlock(w blockWriter) (*hclwrite.Block, []byte) {
+	wf := hclwrite.NewEmptyFile()
+	w(wf.Body())
+
+	bs := wf.Bytes()
+
+	rf, diags := hclwrite.ParseConfig(bs, "", hcl.InitialPos)
+	if diags.HasErrors() || len(rf.Body().Blocks()) == 0 {
+		return nil, nil
+	}
+
+	return rf.Body().Blocks()[0], bs
+}
+
+// patchBody patches the given hclwrite.Body of the original file,
+// with the differences between the rendered original body and the rendered current body,
+// the given notes are written above the rewritten attributes and blocks,
+// which are indexed by the attribute name or the block head.
+func patchBody(wb, ob, cb *hclwrite.Body, notes map[string][]string) {
+	// Patch attributes.
+	oas, cas := ob.Attributes(), cb.Attributes()
+
+	for _, n := range sortedKeys(oas) {
+		if _, exist := cas[n]; !exist {
+			wb.RemoveAttribute(n)
+		}
+	}
+
+	for _, n := range sortedKeys(cas) {
+		ct := cas[n].Expr().BuildTokens(nil)
+
+		if oa, exist := oas[n]; exist && equalTokens(oa.Expr().BuildTokens(nil), ct) {
+			continue
+		}
+
+		wb.SetAttributeRaw(n, ct).AppendLeadComments(notes[n]...)
+	}
+
+	// Patch blocks, pair by the header and the index.
+	obs, cbs, wbs := groupBlocks(ob.Blocks()), groupBlocks(cb.Blocks()), groupBlocks(wb.Blocks())
+
+	for _, k := range blockKeys(cb.Blocks(), ob.Blocks()) {
+		os, cs, ws := obs[k], cbs[k], wbs[k]
+
+		for i := range cs {
+			if i < len(os) && i < len(ws) {
+				if !equalTokens(os[i].BuildTokens(nil), cs[i].BuildTokens(nil)) {
+					patchBody(ws[i].Body(), os[i].Body(), cs[i].Body(), nil)
+					ws[i].AppendLeadComments(notes[blockHead(cs[i])]...)
+				}
+
+				continue
+			}
+
+			wb.AppendBlock(cs[i]).AppendLeadComments(notes[blockHead(cs[i])]...)
+		}
+
+		for i := len(cs); i < len(os) && i < len(ws); i++ {
+			wb.RemoveBlock(ws[i])
+		}
+	}
+}
+
+// patchLocals patches the given hclwrite.Body of a locals block,
+// and records the names of the seen locals,
+// the given notes are written above the rewritten locals.
+func patchLocals(
+	lsBody *hclwrite.Body,
+	filename string,
+	m *configs.Module,
+	seenLocals map[string]struct{},
+	notes map[string][]string,
+) {
+	for _, n := range sortedKeys(lsBody.Attributes()) {
+		seenLocals[n] = struct{}{}
+
+		l, exist := m.Locals[n]
+
+		switch {
+		case !exist:
+			lsBody.RemoveAttribute(n)
+		case l.Tokens == nil || l.DeclRange.Filename != filename:
+			// Rewrite the patched or overridden local.
+			lsBody.SetAttributeRaw(n, tokensForLocal(l)).AppendLeadComments(notes[n]...)
+		}
+	}
+}
+
+// annotateLocals writes the given notes above the given locals of the given hclwrite.Body.
+func annotateLocals(lsBody *hclwrite.Body, locals []*configs.Local, notes map[string][]string) {
+	for _, l := range locals {
+		if attr := lsBody.GetAttribute(l.Name); attr != nil {
+			attr.AppendLeadComments(notes[l.Name]...)
+		}
+	}
+}
+
+// blockHead returns the head of the given hclwrite.Block,
+// which is the label of a dynamic block, or the type of others.
+func blockHead(b *hclwrite.Block) string {
+	if b.Type() == "dynamic" && len(b.Labels()) != 0 {
+		return b.Labels()[0]
+	}
+
+	return b.Type()
+}
+
+// hasHead returns true if the given hclwrite.Body has an attribute or a block with the given head.
+func hasHead(wb *hclwrite.Body, head string) bool {
+	if wb.GetAttribute(head) != nil {
+		return true
+	}
+
+	for _, b := range wb.Blocks() {
+		if blockHead(b) == head {
+			return true
+		}
+	}
+
+	return false
+}
+
+// blockKey returns the key of the given hclwrite.Block,
+// which consists of the type and labels.
+func blockKey(b *hclwrite.Block) string {
+	return strings.Join(append([]string{b.Type()}, b.Labels()...), " ")
+}
+
+func groupBlocks(blks []*hclwrite.Block) map[string][]*hclwrite.Block {
+	r := make(map[string][]*hclwrite.Block, len(blks))
+	for i := range blks {
+		k := blockKey(blks[i])
+		r[k] = append(r[k], blks[i])
+	}
+
+	return r
+}
+
+// blockKeys returns the distinct keys of the given blocks in order.
+func blockKeys(blkss ...[]*hclwrite.Block) []string {
+	var (
+		r    []string
+		seen = make(map[string]struct{})
+	)
+
+	for _, blks := range blkss {
+		for i := range blks {
+			k := blockKey(blks[i])
+			if _, exist := seen[k]; exist {
+				continue
+			}
+
+			seen[k] = struct{}{}
+			r = append(r, k)
+		}
+	}
+
+	return r
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	r := make([]string, 0, len(m))
+	for k := range m {
+		r = append(r, k)
+	}
+
+	sort.Strings(r)
+
+	return r
+}
+
+func equalTokens(a, b hclwrite.Tokens) bool {
+	return bytes.Equal(bytes.TrimSpace(a.Bytes()), bytes.TrimSpace(b.Bytes()))
+}
+
+// hasTerraformSettings returns true if the given file declares any terraform settings.
+func hasTerraformSettings(f *configs.File) bool {
+	return len(f.CoreVersionConstraints) != 0 ||
+		len(f.ActiveExperiments) != 0 ||
+		len(f.Backends) != 0 ||
+		len(f.CloudConfigs) != 0 ||
+		len(f.Encryptions) != 0 ||
+		len(f.RequiredProviders) != 0 ||
+		len(f.ProviderMetas) != 0
+}
+
+// isNativeSyntax returns true if the given filename is in HCL native syntax.
+func isNativeSyntax(filename string) bool {
+	return filepath.Ext(filename) != ".json"
+}
+
+// trimBytes trims the leading and trailing blank lines of the given bytes.
+func trimBytes(bs []byte) []byte {
+	bs = bytes.TrimLeft(bs, "\n")
+	bs = bytes.TrimRight(bs, "\n")
+
+	return append(bs, '\n')
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -25,14 +26,18 @@ func TestWrite(t *testing.T) {
 			continue
 		}
 
-		cfg, err := Load(filepath.Join(testCasesLoadDataDir, tc.Name()))
+		flavor := testCaseFlavor(tc.Name())
+
+		cfg, err := Load(filepath.Join(testCasesLoadDataDir, tc.Name()), WithLoadFlavor(flavor))
 		if !assert.NoErrorf(t, err, "terraform load %s", tc.Name()) {
 			continue
 		}
 
+		opts := []WriteOption{WithFlavor(flavor)}
+
 		var actualBuff bytes.Buffer
 
-		err = Write(cfg, &actualBuff)
+		err = Write(cfg, &actualBuff, opts...)
 		if !assert.NoErrorf(t, err, "terraform write %s", tc.Name()) {
 			continue
 		}
@@ -45,3 +50,143 @@ func TestWrite(t *testing.T) {
 		assert.Equal(t, string(expected), actualBuff.String())
 	}
 }
+
+func TestWrite_jsonSyntax(t *testing.T) {
+	var (
+		testCasesLoadDataDir = filepath.Join("testdata", "load")
+		testCasesDataDir     = filepath.Join("testdata", "write_json")
+	)
+
+	testCases, err := os.ReadDir(testCasesLoadDataDir)
+	if err != nil {
+		t.Fatalf("failed to read testdata: %v", err)
+	}
+
+	for _, tc := range testCases {
+		if !tc.IsDir() {
+			continue
+		}
+
+		flavor := testCaseFlavor(tc.Name())
+
+		cfg, err := Load(filepath.Join(testCasesLoadDataDir, tc.Name()), WithLoadFlavor(flavor))
+		if !assert.NoErrorf(t, err, "terraform load %s", tc.Name()) {
+			continue
+		}
+
+		opts := []WriteOption{WithSyntax(SyntaxJSON), WithFlavor(flavor)}
+
+		files, err := WriteFiles(cfg, opts...)
+		if !assert.NoErrorf(t, err, "terraform write %s", tc.Name()) {
+			continue
+		}
+
+		if !assert.Lenf(t, files, 1, "terraform write %s", tc.Name()) {
+			continue
+		}
+
+		assert.Equal(t, "main"+fileExt(flavor)+".json", files[0].Name)
+
+		expectedDir := filepath.Join(testCasesDataDir, tc.Name())
+
+		expected, err := os.ReadFile(filepath.Join(expectedDir, files[0].Name))
+		if !assert.NoErrorf(t, err, "expected read %s", tc.Name()) {
+			continue
+		}
+
+		assert.Equal(t, string(expected), string(files[0].Bytes))
+
+		// The written configuration must be loadable.
+		_, err = Load(expectedDir, WithLoadFlavor(flavor))
+		assert.NoErrorf(t, err, "terraform load written %s", tc.Name())
+	}
+}
+
+func TestWrite_encryptionWithoutOpenTofu(t *testing.T) {
+	cfg, err := Load(filepath.Join("testdata", "load", "tofu_with_encryption"))
+	if err != nil {
+		t.Fatalf("failed to load terraform configuration: %v", err)
+	}
+
+	err = Write(cfg, &bytes.Buffer{}, WithFlavor(FlavorTerraform))
+	assert.Error(t, err, "encryption block should be rejected with Terraform")
+
+	// Without the flavor, the encryption block is written through.
+	var buf bytes.Buffer
+
+	err = Write(cfg, &buf)
+	if assert.NoError(t, err, "encryption block should be written without the flavor") {
+		assert.Contains(t, buf.String(), "encryption {")
+	}
+}
+
+func TestWriteFiles(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected []string
+		// unchanged indicates whether the files keep the original content.
+		unchanged bool
+	}{
+		{
+			name:      "with_terraform_settings",
+			expected:  []string{"main.tf", "versions.tf"},
+			unchanged: true,
+		},
+		{
+			// The override files are merged away.
+			name:     "with_override",
+			expected: []string{"main.tf"},
+		},
+		{
+			// The JSON files are written back as JSON.
+			name:     "with_json",
+			expected: []string{"generated.tf.json", "main.tf"},
+		},
+		{
+			// The .tofu files take precedence over the .tf files with the same name.
+			name:      "tofu_with_tofu_files",
+			expected:  []string{"main.tofu", "outputs.tofu.json", "variables.tf"},
+			unchanged: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		flavor := testCaseFlavor(tc.name)
+
+		cfg, err := Load(filepath.Join("testdata", "load", tc.name), WithLoadFlavor(flavor))
+		if !assert.NoErrorf(t, err, "terraform load %s", tc.name) {
+			continue
+		}
+
+		files, err := WriteFiles(cfg, WithFlavor(flavor))
+		if !assert.NoErrorf(t, err, "terraform write files %s", tc.name) {
+			continue
+		}
+
+		actual := make([]string, 0, len(files))
+		for i := range files {
+			actual = append(actual, files[i].Name)
+
+			if !tc.unchanged {
+				continue
+			}
+
+			expected, err := os.ReadFile(filepath.Join("testdata", "load", tc.name, files[i].Name))
+			if assert.NoErrorf(t, err, "original read %s", files[i].Name) {
+				assert.Equal(t, string(expected), string(files[i].Bytes))
+			}
+		}
+
+		assert.Equal(t, tc.expected, actual)
+	}
+}
+
+// testCaseFlavor returns the flavor of the given test case,
+// which is FlavorOpenTofu if the test case is prefixed with "tofu_".
+func testCaseFlavor(name string) Flavor {
+	if strings.HasPrefix(name, "tofu_") {
+		return FlavorOpenTofu
+	}
+
+	return FlavorTerraform
+}
@@ -4,6 +4,8 @@ import (
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/hashicorp/terraform/addrs"
+	"github.com/hashicorp/terraform/configs"
 )
 
 func fromHCLTokens(tokens hcl.Tokens, skipNewline bool) hclwrite.Tokens {
@@ -22,3 +24,90 @@ func fromHCLTokens(tokens hcl.Tokens, skipNewline bool) hclwrite.Tokens {
 
 	return r
 }
+
+func tokensForExpression(expr hcl.Expression) hclwrite.Tokens {
+	if e, ok := expr.(hclsyntax.Expression); ok {
+		return hclwrite.TokensForExpression(e)
+	}
+
+	return nil
+}
+
+func tokensForExpressions(exprs []hcl.Expression) hclwrite.Tokens {
+	elems := make([]hclwrite.Tokens, 0, len(exprs))
+	for i := range exprs {
+		elems = append(elems, tokensForExpression(exprs[i]))
+	}
+
+	return hclwrite.TokensForTuple(elems)
+}
+
+func tokensForTraversals(traversals []hcl.Traversal) hclwrite.Tokens {
+	elems := make([]hclwrite.Tokens, 0, len(traversals))
+	for i := range traversals {
+		tr := traversals[i]
+
+		// Convert the relative traversal to be absolute.
+		if ta, ok := tr[0].(hcl.TraverseAttr); ok {
+			tr = append(hcl.Traversal{hcl.TraverseRoot{Name: ta.Name, SrcRange: ta.SrcRange}}, tr[1:]...)
+		}
+
+		elems = append(elems, hclwrite.TokensForTraversal(tr))
+	}
+
+	return hclwrite.TokensForTuple(elems)
+}
+
+func tokensForProviderConfigRef(ref *configs.ProviderConfigRef) hclwrite.Tokens {
+	tr := hcl.Traversal{
+		hcl.TraverseRoot{Name: ref.Name},
+	}
+
+	if ref.Alias != "" {
+		tr = append(tr, hcl.TraverseAttr{Name: ref.Alias})
+	}
+
+	return hclwrite.TokensForTraversal(tr)
+}
+
+func tokensForPassedProviderConfigs(ppcs []configs.PassedProviderConfig) hclwrite.Tokens {
+	attrs := make([]hclwrite.ObjectAttrTokens, 0, len(ppcs))
+	for i := range ppcs {
+		attrs = append(attrs, hclwrite.ObjectAttrTokens{
+			Name:  tokensForProviderConfigRef(ppcs[i].InChild),
+			Value: tokensForProviderConfigRef(ppcs[i].InParent),
+		})
+	}
+
+	return hclwrite.TokensForObject(attrs)
+}
+
+func tokensForIdentifiers(idents []string) hclwrite.Tokens {
+	elems := make([]hclwrite.Tokens, 0, len(idents))
+	for i := range idents {
+		elems = append(elems, hclwrite.TokensForIdentifier(idents[i]))
+	}
+
+	return hclwrite.TokensForTuple(elems)
+}
+
+func tokensForLocalProviderConfigs(lpcs []addrs.LocalProviderConfig) hclwrite.Tokens {
+	elems := make([]hclwrite.Tokens, 0, len(lpcs))
+	for i := range lpcs {
+		elems = append(elems, tokensForProviderConfigRef(&configs.ProviderConfigRef{
+			Name:  lpcs[i].LocalName,
+			Alias: lpcs[i].Alias,
+		}))
+	}
+
+	return hclwrite.TokensForTuple(elems)
+}
+
+func tokensForLocal(l *configs.Local) hclwrite.Tokens {
+	// Write the patched local in structure.
+	if l.Tokens == nil {
+		return tokensForExpression(l.Expr)
+	}
+
+	return fromHCLTokens(l.Tokens, true)
+}
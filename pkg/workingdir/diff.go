@@ -0,0 +1,117 @@
+package workingdir
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/seal-io/tap/pkg/tap"
+	"github.com/seal-io/tap/pkg/terraform"
+)
+
+// Diff writes the changes made by the tap configuration of the given working dir into the given writer,
+// the changes are compared between the terraform configuration before and after applying,
+// grouped by the object address, and annotated with the operations that cause them.
+//
+// Diff does not touch the working dir or the tap directory.
+func Diff(flavor terraform.Flavor, workingDir string, w io.Writer, opts ...tap.LoadOption) error {
+	// Load tap config.
+	cfg, err := loadConfig(flavor, workingDir, opts)
+	if err != nil {
+		return fmt.Errorf("error loading tap configuration: %w", err)
+	}
+
+	// Load terraform config twice,
+	// since applying changes the configuration in place.
+	before, err := terraform.Load(workingDir, terraform.WithLoadFlavor(flavor))
+	if err != nil {
+		return fmt.Errorf("error loading terraform configuration: %w", err)
+	}
+
+	after, err := terraform.Load(workingDir, terraform.WithLoadFlavor(flavor))
+	if err != nil {
+		return fmt.Errorf("error loading terraform configuration: %w", err)
+	}
+
+	// Apply tap configuration.
+	after, report, err := tap.ApplyWithReport(after, cfg)
+	if err != nil {
+		return fmt.Errorf("error applying tap configuration: %w", err)
+	}
+
+	printWarnings(report)
+
+	ocs, err := terraform.Diff(before, after)
+	if err != nil {
+		return err
+	}
+
+	if len(ocs) == 0 {
+		_, err = fmt.Fprintln(w, "No changes.")
+		return err
+	}
+
+	var sb strings.Builder
+
+	for i, oc := range ocs {
+		if i != 0 {
+			sb.WriteByte('\n')
+		}
+
+		fmt.Fprintf(&sb, "%s:\n", oc.Address)
+
+		// Annotate each hunk, which is the consecutive changes caused by the same operations.
+		var prev string
+
+		for _, c := range oc.Changes {
+			var notes []string
+			for _, op := range report.Operations(oc.Address, c.Path) {
+				notes = append(notes, fmt.Sprintf("  # tap: %s %s (%s)\n", op.Mode, op.Path, op.Source))
+			}
+
+			if n := strings.Join(notes, ""); n != prev {
+				sb.WriteString(n)
+				prev = n
+			}
+
+			writeChange(&sb, c)
+		}
+	}
+
+	_, err = io.WriteString(w, sb.String())
+
+	return err
+}
+
+// writeChange writes the given terraform.Change in the form of "  ~ path: before -> after".
+func writeChange(sb *strings.Builder, c terraform.Change) {
+	p := c.PathString()
+	if p == "" {
+		p = "(object)"
+	}
+
+	switch c.Action {
+	case terraform.ChangeCreate:
+		fmt.Fprintf(sb, "  + %s: %s\n", p, changeValue(c.After))
+	case terraform.ChangeDelete:
+		fmt.Fprintf(sb, "  - %s: %s\n", p, changeValue(c.Before))
+	default:
+		fmt.Fprintf(sb, "  ~ %s: %s -> %s\n", p, changeValue(c.Before), changeValue(c.After))
+	}
+}
+
+// changeValue returns the compact JSON representation of the given value.
+func changeValue(v any) string {
+	var buf bytes.Buffer
+
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+
+	if err := enc.Encode(v); err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+
+	return strings.TrimSuffix(buf.String(), "\n")
+}
@@ -0,0 +1,23 @@
+package workingdir
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/seal-io/tap/pkg/terraform"
+)
+
+func TestDiff(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := Diff(terraform.FlavorTerraform, filepath.Join("testdata", "render"), &buf)
+	if assert.NoError(t, err) {
+		assert.Equal(t, `kubernetes_namespace.default:
+  # tap: set /metadata/0/labels (tap.hcl:6)
+  + metadata[0].labels: {"managed-by":"tap"}
+`, buf.String())
+	}
+}
@@ -64,6 +64,61 @@ func extractChdirOption(args []string) (string, []string, error) {
 	return argValue, newArgs, nil
 }
 
+// extractTapVarOptions is a helper function to extract the -tap-var options,
+// which can appear anywhere before the "--" argument, in the form of -tap-var=name=value or -tap-var name=value.
+func extractTapVarOptions(args []string) (map[string]string, []string, error) {
+	const (
+		argName   = "-tap-var"
+		argPrefix = argName + "="
+	)
+
+	var (
+		vars    map[string]string
+		newArgs = make([]string, 0, len(args))
+	)
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		if arg == "--" {
+			newArgs = append(newArgs, args[i:]...)
+			break
+		}
+
+		var kv string
+
+		switch {
+		default:
+			newArgs = append(newArgs, arg)
+			continue
+		case arg == argName || arg == "-"+argName:
+			if i+1 == len(args) {
+				return nil, args, fmt.Errorf("must be followed by a name=value pair, like -tap-var=name=value")
+			}
+
+			i++
+			kv = args[i]
+		case strings.HasPrefix(arg, argPrefix):
+			kv = arg[len(argPrefix):]
+		case strings.HasPrefix(arg, "-"+argPrefix):
+			kv = arg[len(argPrefix)+1:]
+		}
+
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok || k == "" {
+			return nil, args, fmt.Errorf("invalid -tap-var option %q, must be a name=value pair", kv)
+		}
+
+		if vars == nil {
+			vars = map[string]string{}
+		}
+
+		vars[k] = v
+	}
+
+	return vars, newArgs, nil
+}
+
 // cleanDir is a helper function to clean a directory,
 // but keep the state files.
 func cleanDir(tapDir string) error {
@@ -106,8 +161,8 @@ func cleanDir(tapDir string) error {
 // copyDir is a helper function to copy a directory,
 // borrows from https://github.com/hashicorp/terraform/blob/ee58ac1851c8a433005df9863ed47796a9f6b5e7/internal/copy/copy_dir.go#L38-L38.
 //
-// This function is modified to skip the .tap directory and .tf files in the root directory,
-// and not to overwrite any state files.
+// This function is modified to skip the .tap directory and configuration files in the root directory,
+// including the .tofu and .tofu.json files of OpenTofu, and not to overwrite any state files.
 func copyDir(src, dst string) error {
 	if err := os.Mkdir(dst, 0o700); err != nil && !os.IsExist(err) {
 		return fmt.Errorf("failed to create directory %q: %w", dst, err)
@@ -132,8 +187,10 @@ func copyDir(src, dst string) error {
 		case info.IsDir() && info.Name() == ".tap":
 			// Skip .tap directory.
 			return filepath.SkipDir
-		case !info.IsDir() && filepath.Dir(path) == src && filepath.Ext(path) == ".tf":
-			// Skip .tf files in the root directory.
+		case !info.IsDir() && filepath.Dir(path) == src &&
+			(strings.HasSuffix(path, ".tf") || strings.HasSuffix(path, ".tf.json") ||
+				strings.HasSuffix(path, ".tofu") || strings.HasSuffix(path, ".tofu.json")):
+			// Skip .tf, .tf.json, .tofu and .tofu.json files in the root directory.
 			return nil
 		}
 
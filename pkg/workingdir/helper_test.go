@@ -42,14 +42,61 @@ func Test_copyDir_cleanDir(t *testing.T) {
 	{
 		dstFs := afero.Afero{Fs: afero.NewBasePathFs(afero.NewOsFs(), dst)}
 
-		found, err := afero.Glob(dstFs, "*.tf")
-		if err != nil {
-			t.Fatalf("failed to glob terraform files: %v", err)
-		}
+		for _, pattern := range []string{"*.tf", "*.tf.json", "*.tofu", "*.tofu.json"} {
+			found, err := afero.Glob(dstFs, pattern)
+			if err != nil {
+				t.Fatalf("failed to glob terraform files: %v", err)
+			}
 
-		assert.Len(t, found, 0, "unexpected number of terraform files")
+			assert.Len(t, found, 0, "unexpected number of terraform files: %s", pattern)
+		}
 	}
 
 	err = cleanDir(filepath.Join(src, ".tap"))
 	assert.NoError(t, err, "failed to clean directory")
 }
+
+func Test_extractTapVarOptions(t *testing.T) {
+	testCases := []struct {
+		given        []string
+		expectedVars map[string]string
+		expectedArgs []string
+		expectedErr  bool
+	}{
+		{
+			given:        []string{"-chdir=foo", "plan"},
+			expectedArgs: []string{"-chdir=foo", "plan"},
+		},
+		{
+			given:        []string{"plan", "-tap-var=env=production", "-tap-var", "apps=[\"a\"]", "-out=plan"},
+			expectedVars: map[string]string{"env": "production", "apps": `["a"]`},
+			expectedArgs: []string{"plan", "-out=plan"},
+		},
+		{
+			given:        []string{"plan", "--tap-var=url=a=b", "--", "-tap-var=ignored=true"},
+			expectedVars: map[string]string{"url": "a=b"},
+			expectedArgs: []string{"plan", "--", "-tap-var=ignored=true"},
+		},
+		{
+			given:       []string{"plan", "-tap-var=env"},
+			expectedErr: true,
+		},
+		{
+			given:       []string{"plan", "-tap-var"},
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		vars, args, err := extractTapVarOptions(tc.given)
+		if tc.expectedErr {
+			assert.Error(t, err, tc.given)
+			continue
+		}
+
+		if assert.NoError(t, err, tc.given) {
+			assert.Equal(t, tc.expectedVars, vars, tc.given)
+			assert.Equal(t, tc.expectedArgs, args, tc.given)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package workingdir
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/seal-io/tap/pkg/tap"
+	"github.com/seal-io/tap/pkg/terraform"
+)
+
+// Render writes the terraform configuration of the given working dir patched by its tap configuration
+// into the given writer, the written files are concatenated in order.
+//
+// Render does not touch the working dir or the tap directory.
+func Render(flavor terraform.Flavor, workingDir string, w io.Writer, opts ...tap.LoadOption) error {
+	// Load tap config.
+	cfg, err := loadConfig(flavor, workingDir, opts)
+	if err != nil {
+		return fmt.Errorf("error loading tap configuration: %w", err)
+	}
+
+	// Load terraform config.
+	tfcfg, err := terraform.Load(workingDir, terraform.WithLoadFlavor(flavor))
+	if err != nil {
+		return fmt.Errorf("error loading terraform configuration: %w", err)
+	}
+
+	// Apply tap configuration.
+	tfcfg, report, err := tap.ApplyWithReport(tfcfg, cfg)
+	if err != nil {
+		return fmt.Errorf("error applying tap configuration: %w", err)
+	}
+
+	printWarnings(report)
+
+	files, err := writeFiles(flavor, tfcfg, cfg, report)
+	if err != nil {
+		return err
+	}
+
+	for i := range files {
+		if i != 0 {
+			if _, err = w.Write([]byte{'\n'}); err != nil {
+				return err
+			}
+		}
+
+		if _, err = w.Write(files[i].Bytes); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Export writes a standalone working copy of the given working dir into the given output dir,
+// in which the terraform configuration is patched by the tap configuration of the working dir,
+// and the outcomes of the operations are recorded into the ReportFile,
+// the tap configuration files are excluded, so that the copy is not patched again.
+//
+// The output dir must be empty or not exist, and must be outside the working dir.
+func Export(flavor terraform.Flavor, workingDir, outDir string, opts ...tap.LoadOption) error {
+	wd, err := filepath.Abs(workingDir)
+	if err != nil {
+		return err
+	}
+
+	od, err := filepath.Abs(outDir)
+	if err != nil {
+		return err
+	}
+
+	if rel, err := filepath.Rel(wd, od); err == nil && !strings.HasPrefix(rel, "..") {
+		return fmt.Errorf("output directory %s must be outside the working directory", outDir)
+	}
+
+	ents, err := os.ReadDir(od)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error reading output directory: %w", err)
+	}
+
+	if len(ents) != 0 {
+		return fmt.Errorf("output directory %s is not empty", outDir)
+	}
+
+	// Load tap config.
+	cfg, err := loadConfig(flavor, wd, opts)
+	if err != nil {
+		return fmt.Errorf("error loading tap configuration: %w", err)
+	}
+
+	if err = writeDir(flavor, wd, od, cfg); err != nil {
+		return err
+	}
+
+	// Exclude the tap configuration files.
+	names, err := tap.ConfigFiles(od, tap.WithFlavor(flavor))
+	if err != nil {
+		return err
+	}
+
+	for _, n := range names {
+		if err = os.Remove(filepath.Join(od, n)); err != nil {
+			return fmt.Errorf("error excluding tap configuration %s: %w", n, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package workingdir
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/seal-io/tap/pkg/terraform"
+)
+
+func TestRender(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := Render(terraform.FlavorTerraform, filepath.Join("testdata", "render"), &buf)
+	if assert.NoError(t, err) {
+		assert.Contains(t, buf.String(), `managed-by = "tap"`)
+	}
+
+	_, err = os.Stat(filepath.Join("testdata", "render", ".tap"))
+	assert.True(t, os.IsNotExist(err), "render should not create the tap directory")
+}
+
+func TestExport(t *testing.T) {
+	src := filepath.Join("testdata", "render")
+	dst := filepath.Join(t.TempDir(), "out")
+
+	err := Export(terraform.FlavorTerraform, src, dst)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	bs, err := os.ReadFile(filepath.Join(dst, "main.tf"))
+	if assert.NoError(t, err) {
+		assert.Contains(t, string(bs), `managed-by = "tap"`)
+	}
+
+	assert.FileExists(t, filepath.Join(dst, ReportFile))
+	assert.NoFileExists(t, filepath.Join(dst, "tap.hcl"), "tap configuration should be excluded")
+
+	// Export into a non-empty directory or the working directory.
+	assert.Error(t, Export(terraform.FlavorTerraform, src, dst))
+	assert.Error(t, Export(terraform.FlavorTerraform, src, filepath.Join(src, "out")))
+}
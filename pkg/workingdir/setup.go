@@ -1,6 +1,7 @@
 package workingdir
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -9,26 +10,29 @@ import (
 	"github.com/seal-io/tap/pkg/terraform"
 )
 
-// Setup prepares the working directory.
-func Setup(args []string) ([]string, error) {
-	// Get root dir.
-	rootDir, err := os.Getwd()
-	if err != nil {
-		return nil, err
-	}
+// EnvOutputSyntax is the environment variable to override the output syntax of the tap configuration.
+const EnvOutputSyntax = "TAP_OUTPUT_SYNTAX"
 
+// ReportFile is the name of the file to record the outcomes of the tap operations, under the tap directory.
+const ReportFile = "tap-report.json"
+
+// Setup prepares the working directory for the given flavor of CLI.
+func Setup(flavor terraform.Flavor, args []string) ([]string, error) {
 	// Get working dir.
-	workingDir, args, err := extractChdirOption(args)
+	workingDir, args, err := Dir(args)
 	if err != nil {
 		return nil, err
 	}
 
-	if workingDir == "" {
-		workingDir = rootDir
+	// Get tap variables,
+	// which are not recognized by the delegated CLI.
+	vars, args, err := Vars(args)
+	if err != nil {
+		return nil, err
 	}
 
 	// Load tap config.
-	cfg, err := tap.Load(workingDir)
+	cfg, err := loadConfig(flavor, workingDir, []tap.LoadOption{tap.WithVariables(vars)})
 	if err != nil {
 		return nil, fmt.Errorf("error loading tap configuration: %w", err)
 	}
@@ -40,43 +44,160 @@ func Setup(args []string) ([]string, error) {
 			return nil, fmt.Errorf("error preparing the tap directory")
 		}
 
-		// Load terraform config.
-		tfcfg, err := terraform.Load(workingDir)
-		if err != nil {
-			return nil, fmt.Errorf("error loading terraform configuration: %w", err)
+		// Write the patched working dir into the tap dir.
+		if err = writeDir(flavor, workingDir, tapDir, cfg); err != nil {
+			return nil, err
 		}
 
-		// Apply tap configuration.
-		tfcfg, err = tap.Apply(tfcfg, cfg)
+		// Mutate the working dir if tap is configured.
+		workingDir = tapDir
+	}
+
+	// Create new arguments.
+	newArgs := make([]string, len(args)+1)
+	newArgs[0] = "-chdir=" + workingDir
+	copy(newArgs[1:], args)
+
+	return newArgs, nil
+}
+
+// Dir returns the working directory specified by the -chdir option of the given arguments,
+// defaults to the current directory, and the arguments without the -chdir option.
+func Dir(args []string) (string, []string, error) {
+	workingDir, args, err := extractChdirOption(args)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if workingDir == "" {
+		workingDir, err = os.Getwd()
 		if err != nil {
-			return nil, fmt.Errorf("error applying tap configuration: %w", err)
+			return "", nil, err
 		}
+	}
 
-		// Copy the working dir to the tap dir.
-		if err = copyDir(workingDir, tapDir); err != nil {
-			return nil, fmt.Errorf("error copying the working directory")
-		}
+	return workingDir, args, nil
+}
+
+// Vars returns the raw values of the tap variables specified by the -tap-var options of the given arguments,
+// and the arguments without the -tap-var options.
+func Vars(args []string) (map[string]string, []string, error) {
+	return extractTapVarOptions(args)
+}
+
+// loadConfig loads the tap configuration of the given working dir for the given flavor of CLI.
+func loadConfig(flavor terraform.Flavor, workingDir string, opts []tap.LoadOption) (*tap.Config, error) {
+	return tap.Load(workingDir, append([]tap.LoadOption{tap.WithFlavor(flavor)}, opts...)...)
+}
+
+// writeDir copies the given working dir into the given output dir,
+// and writes the terraform configuration patched by the given tap configuration into it,
+// the outcomes of the operations are recorded into the ReportFile even if applying fails.
+//
+// The written terraform configuration is reloaded and verified,
+// to prevent losing or altering anything silently.
+func writeDir(flavor terraform.Flavor, workingDir, outDir string, cfg *tap.Config) error {
+	// Load terraform config.
+	tfcfg, err := terraform.Load(workingDir, terraform.WithLoadFlavor(flavor))
+	if err != nil {
+		return fmt.Errorf("error loading terraform configuration: %w", err)
+	}
+
+	// Apply tap configuration,
+	// and record the outcomes of the operations even if applying fails.
+	tfcfg, report, err := tap.ApplyWithReport(tfcfg, cfg)
+	if rerr := writeReport(outDir, report); rerr != nil {
+		return fmt.Errorf("error writing tap report: %w", rerr)
+	}
+
+	if err != nil {
+		return fmt.Errorf("error applying tap configuration: %w", err)
+	}
+
+	printWarnings(report)
+
+	// Copy the working dir to the output dir.
+	if err = copyDir(workingDir, outDir); err != nil {
+		return fmt.Errorf("error copying the working directory")
+	}
 
-		// Write the terraform configuration.
-		f, err := os.OpenFile(filepath.Join(tapDir, "main.tf"), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	files, err := writeFiles(flavor, tfcfg, cfg, report)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		err = os.WriteFile(filepath.Join(outDir, filepath.Base(f.Name)), f.Bytes, 0o644)
 		if err != nil {
-			return nil, fmt.Errorf("error creating terraform configuration: %w", err)
+			return fmt.Errorf("error writing terraform configuration %s: %w", f.Name, err)
 		}
+	}
+
+	// Verify the written terraform configuration,
+	// to prevent losing or altering anything silently.
+	wtfcfg, err := terraform.Load(outDir, terraform.WithLoadFlavor(flavor))
+	if err != nil {
+		return fmt.Errorf("error reloading written terraform configuration: %w", err)
+	}
 
-		defer func() { _ = f.Close() }()
+	if err = terraform.Verify(tfcfg, wtfcfg); err != nil {
+		return fmt.Errorf("error verifying written terraform configuration: %w", err)
+	}
 
-		if err = terraform.Write(tfcfg, f); err != nil {
-			return nil, fmt.Errorf("error writing terraform configuration: %w", err)
-		}
+	return nil
+}
 
-		// Mutate the working dir if tap is configured.
-		workingDir = tapDir
+// writeFiles writes the patched terraform configuration into files,
+// keeps the layout of the original files, or writes into a single JSON file,
+// which is selected by the given tap configuration or EnvOutputSyntax.
+func writeFiles(
+	flavor terraform.Flavor,
+	tfcfg *terraform.Config,
+	cfg *tap.Config,
+	report *tap.Report,
+) ([]terraform.File, error) {
+	syntax := terraform.SyntaxHCL
+	if cfg != nil && cfg.OutputSyntax != "" {
+		syntax = terraform.Syntax(cfg.OutputSyntax)
 	}
 
-	// Create new arguments.
-	newArgs := make([]string, len(args)+1)
-	newArgs[0] = "-chdir=" + workingDir
-	copy(newArgs[1:], args)
+	if s := os.Getenv(EnvOutputSyntax); s != "" {
+		syntax = terraform.Syntax(s)
+	}
 
-	return newArgs, nil
+	opts := []terraform.WriteOption{
+		terraform.WithFlavor(flavor),
+		terraform.WithSyntax(syntax),
+	}
+	if cfg != nil && cfg.Provenance {
+		opts = append(opts, terraform.WithAnnotations(report.Annotations()))
+	}
+
+	files, err := terraform.WriteFiles(tfcfg, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("error writing terraform configuration: %w", err)
+	}
+
+	return files, nil
+}
+
+// printWarnings prints the warnings of the given tap.Report to stderr.
+func printWarnings(report *tap.Report) {
+	for _, w := range report.Warnings {
+		_, _ = fmt.Fprintf(os.Stderr, "Warning: %s\n", w)
+	}
+}
+
+// writeReport writes the given tap.Report into the tap dir.
+func writeReport(tapDir string, report *tap.Report) error {
+	bs, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(tapDir, 0o755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(tapDir, ReportFile), append(bs, '\n'), 0o644)
 }
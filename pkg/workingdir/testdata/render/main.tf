@@ -0,0 +1,5 @@
+resource "kubernetes_namespace" "default" {
+  metadata {
+    name = "default"
+  }
+}
@@ -0,0 +1,12 @@
+tap {
+  path_syntax = "json_pointer"
+}
+
+resource "kubernetes_namespace" {
+  set {
+    path  = "/metadata/0/labels"
+    value = {
+      managed-by = "tap"
+    }
+  }
+}
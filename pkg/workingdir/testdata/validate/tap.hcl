@@ -0,0 +1,30 @@
+tap {
+  path_syntax = "json_pointer"
+}
+
+resource "kubernetes_namespace" {
+  continue_on_error = true
+
+  remove {
+    path = "/metadata/0/labels"
+  }
+}
+
+resource "kubernetes_service" {
+  remove {
+    path = "/spec"
+  }
+}
+
+variable "missing_*" {
+  remove {
+    path = "/default"
+  }
+}
+
+resource "kubernetes_namespace" {
+  replace {
+    path  = "/spec/0/finalizers"
+    value = ["kubernetes"]
+  }
+}
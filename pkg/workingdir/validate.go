@@ -0,0 +1,232 @@
+package workingdir
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/hcl/v2"
+
+	"github.com/seal-io/tap/pkg/tap"
+	"github.com/seal-io/tap/pkg/terraform"
+)
+
+// Validate checks the tap configuration of the given working dir against its terraform configuration,
+// writes the diagnostics into the given writer in the same way as Terraform,
+// or in JSON if asJSON is true, returns an error if any error diagnostic is found.
+//
+// Validate does not touch the working dir or the tap directory.
+func Validate(flavor terraform.Flavor, workingDir string, w io.Writer, asJSON bool, opts ...tap.LoadOption) error {
+	diags := validate(flavor, workingDir, opts)
+
+	var err error
+	if asJSON {
+		err = writeDiagnosticsJSON(w, workingDir, diags)
+	} else {
+		err = writeDiagnostics(w, workingDir, diags)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if diags.HasErrors() {
+		return errors.New("invalid tap configuration")
+	}
+
+	return nil
+}
+
+// validate returns the diagnostics of loading and applying the tap configuration of the given working dir.
+func validate(flavor terraform.Flavor, workingDir string, opts []tap.LoadOption) hcl.Diagnostics {
+	// Load tap config.
+	cfg, err := loadConfig(flavor, workingDir, opts)
+	if err != nil {
+		return errorDiagnostics("Failed to load tap configuration", err)
+	}
+
+	if cfg == nil {
+		return hcl.Diagnostics{
+			{
+				Severity: hcl.DiagWarning,
+				Summary:  "No tap configuration",
+				Detail:   "No tap.hcl or *_tap.hcl file with a tap block is found.",
+			},
+		}
+	}
+
+	// Load terraform config.
+	tfcfg, err := terraform.Load(workingDir, terraform.WithLoadFlavor(flavor))
+	if err != nil {
+		return errorDiagnostics("Failed to load terraform configuration", err)
+	}
+
+	return tap.Validate(tfcfg, cfg)
+}
+
+// errorDiagnostics returns the hcl.Diagnostics wrapped in the given error,
+// or an error diagnostic with the given summary.
+func errorDiagnostics(summary string, err error) hcl.Diagnostics {
+	var diags hcl.Diagnostics
+	if errors.As(err, &diags) {
+		return diags
+	}
+
+	return hcl.Diagnostics{
+		{
+			Severity: hcl.DiagError,
+			Summary:  summary,
+			Detail:   err.Error(),
+		},
+	}
+}
+
+// diagnosticFiles returns the files referred by the given diagnostics,
+// which are used to render the source snippets.
+func diagnosticFiles(workingDir string, diags hcl.Diagnostics) map[string]*hcl.File {
+	files := map[string]*hcl.File{}
+
+	for _, d := range diags {
+		if d.Subject == nil {
+			continue
+		}
+
+		fn := d.Subject.Filename
+		if _, exist := files[fn]; exist {
+			continue
+		}
+
+		p := fn
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(workingDir, p)
+		}
+
+		bs, err := os.ReadFile(p)
+		if err != nil {
+			continue
+		}
+
+		files[fn] = &hcl.File{Bytes: bs}
+	}
+
+	return files
+}
+
+// writeDiagnostics writes the given diagnostics with the source snippets into the given writer.
+func writeDiagnostics(w io.Writer, workingDir string, diags hcl.Diagnostics) error {
+	if len(diags) == 0 {
+		_, err := fmt.Fprintln(w, "Success! The tap configuration is valid.")
+		return err
+	}
+
+	dw := hcl.NewDiagnosticTextWriter(w, diagnosticFiles(workingDir, diags), 78, false)
+
+	return dw.WriteDiagnostics(diags)
+}
+
+type (
+	jsonDiagnostics struct {
+		Valid        bool             `json:"valid"`
+		ErrorCount   int              `json:"error_count"`
+		WarningCount int              `json:"warning_count"`
+		Diagnostics  []jsonDiagnostic `json:"diagnostics"`
+	}
+
+	jsonDiagnostic struct {
+		Severity string       `json:"severity"`
+		Summary  string       `json:"summary"`
+		Detail   string       `json:"detail,omitempty"`
+		Range    *jsonRange   `json:"range,omitempty"`
+		Snippet  *jsonSnippet `json:"snippet,omitempty"`
+	}
+
+	jsonRange struct {
+		Filename string  `json:"filename"`
+		Start    jsonPos `json:"start"`
+		End      jsonPos `json:"end"`
+	}
+
+	jsonPos struct {
+		Line   int `json:"line"`
+		Column int `json:"column"`
+		Byte   int `json:"byte"`
+	}
+
+	jsonSnippet struct {
+		Code      string `json:"code"`
+		StartLine int    `json:"start_line"`
+	}
+)
+
+// writeDiagnosticsJSON writes the given diagnostics in the same form as "terraform validate -json".
+func writeDiagnosticsJSON(w io.Writer, workingDir string, diags hcl.Diagnostics) error {
+	files := diagnosticFiles(workingDir, diags)
+
+	r := jsonDiagnostics{
+		Diagnostics: make([]jsonDiagnostic, 0, len(diags)),
+	}
+
+	for _, d := range diags {
+		jd := jsonDiagnostic{
+			Severity: "error",
+			Summary:  d.Summary,
+			Detail:   d.Detail,
+		}
+
+		if d.Severity == hcl.DiagWarning {
+			jd.Severity = "warning"
+			r.WarningCount++
+		} else {
+			r.ErrorCount++
+		}
+
+		if s := d.Subject; s != nil {
+			jd.Range = &jsonRange{
+				Filename: s.Filename,
+				Start:    jsonPos{Line: s.Start.Line, Column: s.Start.Column, Byte: s.Start.Byte},
+				End:      jsonPos{Line: s.End.Line, Column: s.End.Column, Byte: s.End.Byte},
+			}
+
+			if f := files[s.Filename]; f != nil {
+				jd.Snippet = &jsonSnippet{
+					Code:      snippetCode(f.Bytes, *s),
+					StartLine: s.Start.Line,
+				}
+			}
+		}
+
+		r.Diagnostics = append(r.Diagnostics, jd)
+	}
+
+	r.Valid = r.ErrorCount == 0
+
+	bs, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(append(bs, '\n'))
+
+	return err
+}
+
+// snippetCode returns the whole lines of the given source covered by the given range.
+func snippetCode(src []byte, rng hcl.Range) string {
+	start, end := rng.Start.Byte, rng.End.Byte
+	if start < 0 || end > len(src) || start > end {
+		return ""
+	}
+
+	for start > 0 && src[start-1] != '\n' {
+		start--
+	}
+
+	for end < len(src) && src[end] != '\n' {
+		end++
+	}
+
+	return string(src[start:end])
+}
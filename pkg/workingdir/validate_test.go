@@ -0,0 +1,61 @@
+package workingdir
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/seal-io/tap/pkg/terraform"
+)
+
+func TestValidate(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := Validate(terraform.FlavorTerraform, filepath.Join("testdata", "render"), &buf, false)
+	if assert.NoError(t, err) {
+		assert.Contains(t, buf.String(), "Success!")
+	}
+
+	buf.Reset()
+
+	err = Validate(terraform.FlavorTerraform, filepath.Join("testdata", "validate"), &buf, false)
+	if assert.Error(t, err) {
+		assert.Contains(t, buf.String(), "Warning: Unknown resource type")
+		assert.Contains(t, buf.String(), "Warning: Patch matches no objects")
+		assert.Contains(t, buf.String(), "Error: Unreachable path")
+		assert.Contains(t, buf.String(), `  13: resource "kubernetes_service" {`)
+	}
+}
+
+func TestValidate_json(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := Validate(terraform.FlavorTerraform, filepath.Join("testdata", "validate"), &buf, true)
+	if !assert.Error(t, err) {
+		return
+	}
+
+	var r jsonDiagnostics
+	if !assert.NoError(t, json.Unmarshal(buf.Bytes(), &r)) {
+		return
+	}
+
+	assert.False(t, r.Valid)
+	assert.Equal(t, 1, r.ErrorCount)
+	assert.Equal(t, 2, r.WarningCount)
+
+	if assert.Len(t, r.Diagnostics, 3) {
+		d := r.Diagnostics[2]
+		assert.Equal(t, "error", d.Severity)
+		assert.Equal(t, "Unreachable path", d.Summary)
+
+		if assert.NotNil(t, d.Range) && assert.NotNil(t, d.Snippet) {
+			assert.Equal(t, "tap.hcl", d.Range.Filename)
+			assert.Equal(t, 26, d.Range.Start.Line)
+			assert.Equal(t, "  replace {", d.Snippet.Code)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+package hclsyntax
+
+// IsHiddenAttribute returns true if the given named attribute
+// has been hidden by the previous PartialContent call.
+func (b *Body) IsHiddenAttribute(name string) bool {
+	_, hidden := b.hiddenAttrs[name]
+	return hidden
+}
+
+// IsHiddenBlock returns true if the given typed block
+// has been hidden by the previous PartialContent call.
+func (b *Body) IsHiddenBlock(typeName string) bool {
+	_, hidden := b.hiddenBlocks[typeName]
+	return hidden
+}
@@ -0,0 +1,35 @@
+package hclwrite
+
+import (
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// AppendLeadComments appends the given lines as "#" comments above the attribute.
+func (a *Attribute) AppendLeadComments(lines ...string) {
+	appendComments(a.leadComments, lines)
+}
+
+// AppendLeadComments appends the given lines as "#" comments above the block.
+func (b *Block) AppendLeadComments(lines ...string) {
+	appendComments(b.leadComments, lines)
+}
+
+func appendComments(n *node, lines []string) {
+	if n == nil || len(lines) == 0 {
+		return
+	}
+
+	c, ok := n.content.(*comments)
+	if !ok {
+		return
+	}
+
+	for _, l := range lines {
+		c.tokens = append(c.tokens, &Token{
+			Type:  hclsyntax.TokenComment,
+			Bytes: []byte("# " + strings.TrimSpace(l) + "\n"),
+		})
+	}
+}
@@ -50,7 +50,7 @@ func tokensForExpression(expr hclsyntax.Expression, quoted bool) (tks hclsyntax.
 	case *hclsyntax.TemplateJoinExpr:
 		return TokensForTemplateJoinExpr(e)
 	case *hclsyntax.TemplateWrapExpr:
-		return TokensForTemplateWrapExpr(e)
+		return TokensForTemplateWrapExpr(e, quoted)
 	case *hclsyntax.TupleConsExpr:
 		return TokensForTupleConsExpr(e)
 	case *hclsyntax.UnaryOpExpr:
@@ -184,24 +184,27 @@ func TokensForForExpr(e *hclsyntax.ForExpr) (tks hclsyntax.Tokens) {
 		hclsyntax.Token{
 			Type:  hclsyntax.TokenIdent,
 			Bytes: []byte("for"),
-		},
-		hclsyntax.Token{
-			Type:  hclsyntax.TokenIdent,
-			Bytes: []byte(e.KeyVar),
 		})
 
-	if e.ValVar != "" {
+	// The key variable is omitted if only the value variable is declared.
+	if e.KeyVar != "" {
 		tks = append(tks,
 			hclsyntax.Token{
-				Type:  hclsyntax.TokenComma,
-				Bytes: []byte{','},
+				Type:  hclsyntax.TokenIdent,
+				Bytes: []byte(e.KeyVar),
 			},
 			hclsyntax.Token{
-				Type:  hclsyntax.TokenIdent,
-				Bytes: []byte(e.ValVar),
+				Type:  hclsyntax.TokenComma,
+				Bytes: []byte{','},
 			})
 	}
 
+	tks = append(tks,
+		hclsyntax.Token{
+			Type:  hclsyntax.TokenIdent,
+			Bytes: []byte(e.ValVar),
+		})
+
 	tks = append(tks, hclsyntax.Token{
 		Type:  hclsyntax.TokenIdent,
 		Bytes: []byte("in"),
@@ -525,7 +528,14 @@ func TokensForTemplateJoinExpr(e *hclsyntax.TemplateJoinExpr) (tks hclsyntax.Tok
 	return
 }
 
-func TokensForTemplateWrapExpr(e *hclsyntax.TemplateWrapExpr) (tks hclsyntax.Tokens) {
+func TokensForTemplateWrapExpr(e *hclsyntax.TemplateWrapExpr, quoted bool) (tks hclsyntax.Tokens) {
+	if !quoted {
+		tks = append(tks, hclsyntax.Token{
+			Type:  hclsyntax.TokenOQuote,
+			Bytes: []byte{'"'},
+		})
+	}
+
 	tks = append(tks, hclsyntax.Token{
 		Type:  hclsyntax.TokenTemplateInterp,
 		Bytes: []byte("${"),
@@ -538,6 +548,13 @@ func TokensForTemplateWrapExpr(e *hclsyntax.TemplateWrapExpr) (tks hclsyntax.Tok
 		Bytes: []byte("}"),
 	})
 
+	if !quoted {
+		tks = append(tks, hclsyntax.Token{
+			Type:  hclsyntax.TokenCQuote,
+			Bytes: []byte{'"'},
+		})
+	}
+
 	return
 }
 
@@ -0,0 +1,194 @@
+package json
+
+import (
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// BodyRange returns the range of the JSON object that backs the given body,
+// brace-to-brace.
+//
+// If the given hcl.Body is not a JSON body, this function returns false.
+func BodyRange(bd hcl.Body) (hcl.Range, bool) {
+	b, ok := bd.(*body)
+	if !ok {
+		return hcl.Range{}, false
+	}
+
+	return b.val.Range(), true
+}
+
+// ToSyntaxBody converts the given JSON body into an attribute-only *hclsyntax.Body,
+// all properties of the backing JSON object, including the hidden ones,
+// become attributes of the result, except the "//" comment properties.
+//
+// Since the JSON syntax cannot distinguish blocks from object attributes without a schema,
+// nested blocks are represented as object or tuple attributes.
+//
+// If the given hcl.Body is not a JSON body, this function returns false.
+func ToSyntaxBody(bd hcl.Body) (*hclsyntax.Body, bool) {
+	b, ok := bd.(*body)
+	if !ok {
+		return nil, false
+	}
+
+	ov, ok := b.val.(*objectVal)
+	if !ok {
+		return nil, false
+	}
+
+	repeated := map[string]*hclsyntax.TupleConsExpr{}
+
+	sb := &hclsyntax.Body{
+		Attributes: hclsyntax.Attributes{},
+		Blocks:     hclsyntax.Blocks{},
+		SrcRange:   ov.SrcRange,
+		EndRange:   ov.CloseRange,
+	}
+
+	for _, attr := range ov.Attrs {
+		if attr.Name == "//" {
+			continue
+		}
+
+		expr := toSyntaxExpression(attr.Value)
+
+		// Repeated properties are allowed in JSON syntax to declare multiple blocks,
+		// collect them into a tuple.
+		if prev, exist := sb.Attributes[attr.Name]; exist {
+			tc, ok := repeated[attr.Name]
+			if !ok {
+				tc = &hclsyntax.TupleConsExpr{
+					Exprs:     []hclsyntax.Expression{prev.Expr},
+					SrcRange:  prev.Expr.Range(),
+					OpenRange: prev.Expr.StartRange(),
+				}
+				repeated[attr.Name] = tc
+			}
+			tc.Exprs = append(tc.Exprs, expr)
+			tc.SrcRange = hcl.RangeBetween(tc.SrcRange, expr.Range())
+			prev.Expr = tc
+			prev.SrcRange = hcl.RangeBetween(prev.SrcRange, expr.Range())
+
+			continue
+		}
+
+		sb.Attributes[attr.Name] = &hclsyntax.Attribute{
+			Name:        attr.Name,
+			Expr:        expr,
+			SrcRange:    hcl.RangeBetween(attr.NameRange, attr.Value.Range()),
+			NameRange:   attr.NameRange,
+			EqualsRange: attr.NameRange,
+		}
+	}
+
+	return sb, true
+}
+
+// ToSyntaxExpression converts the given JSON expression into a hclsyntax.Expression,
+// strings are parsed as templates in the same way as the JSON expression evaluation.
+//
+// If the given hcl.Expression is not a JSON expression, this function returns false.
+func ToSyntaxExpression(expr hcl.Expression) (hclsyntax.Expression, bool) {
+	e, ok := expr.(*expression)
+	if !ok {
+		return nil, false
+	}
+
+	return toSyntaxExpression(e.src), true
+}
+
+func toSyntaxExpression(n node) hclsyntax.Expression {
+	switch v := n.(type) {
+	case *objectVal:
+		items := make([]hclsyntax.ObjectConsItem, 0, len(v.Attrs))
+
+		for _, attr := range v.Attrs {
+			var key hclsyntax.Expression
+			if hclsyntax.ValidIdentifier(attr.Name) {
+				key = &hclsyntax.ScopeTraversalExpr{
+					Traversal: hcl.Traversal{
+						hcl.TraverseRoot{
+							Name:     attr.Name,
+							SrcRange: attr.NameRange,
+						},
+					},
+					SrcRange: attr.NameRange,
+				}
+			} else {
+				key = toSyntaxExpression(&stringVal{
+					Value:    attr.Name,
+					SrcRange: attr.NameRange,
+				})
+			}
+
+			items = append(items, hclsyntax.ObjectConsItem{
+				KeyExpr: &hclsyntax.ObjectConsKeyExpr{
+					Wrapped: key,
+				},
+				ValueExpr: toSyntaxExpression(attr.Value),
+			})
+		}
+
+		return &hclsyntax.ObjectConsExpr{
+			Items:     items,
+			SrcRange:  v.SrcRange,
+			OpenRange: v.OpenRange,
+		}
+	case *arrayVal:
+		exprs := make([]hclsyntax.Expression, 0, len(v.Values))
+
+		for _, av := range v.Values {
+			exprs = append(exprs, toSyntaxExpression(av))
+		}
+
+		return &hclsyntax.TupleConsExpr{
+			Exprs:     exprs,
+			SrcRange:  v.SrcRange,
+			OpenRange: v.OpenRange,
+		}
+	case *stringVal:
+		if strings.Contains(v.Value, "${") || strings.Contains(v.Value, "%{") {
+			expr, diags := hclsyntax.ParseTemplate(
+				[]byte(v.Value),
+				v.SrcRange.Filename,
+				hcl.Pos{
+					Line:   v.SrcRange.Start.Line,
+					Byte:   v.SrcRange.Start.Byte + 1,
+					Column: v.SrcRange.Start.Column + 1,
+				},
+			)
+			if !diags.HasErrors() {
+				return expr
+			}
+		}
+
+		return &hclsyntax.LiteralValueExpr{
+			Val:      cty.StringVal(v.Value),
+			SrcRange: v.SrcRange,
+		}
+	case *numberVal:
+		return &hclsyntax.LiteralValueExpr{
+			Val:      cty.NumberVal(v.Value),
+			SrcRange: v.SrcRange,
+		}
+	case *booleanVal:
+		return &hclsyntax.LiteralValueExpr{
+			Val:      cty.BoolVal(v.Value),
+			SrcRange: v.SrcRange,
+		}
+	case *nullVal:
+		return &hclsyntax.LiteralValueExpr{
+			Val:      cty.NullVal(cty.DynamicPseudoType),
+			SrcRange: v.SrcRange,
+		}
+	default:
+		return &hclsyntax.LiteralValueExpr{
+			Val:      cty.DynamicVal,
+			SrcRange: n.Range(),
+		}
+	}
+}
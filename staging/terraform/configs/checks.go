@@ -156,6 +156,8 @@ type Check struct {
 	DataResource *Resource
 	Asserts      []*CheckRule
 
+	Config hcl.Body
+
 	DeclRange hcl.Range
 }
 
@@ -177,6 +179,7 @@ func decodeCheckBlock(block *hcl.Block, override bool) (*Check, hcl.Diagnostics)
 
 	check := &Check{
 		Name:      block.Labels[0],
+		Config:    block.Body,
 		DeclRange: block.DefRange,
 	}
 
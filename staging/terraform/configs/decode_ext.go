@@ -0,0 +1,29 @@
+package configs
+
+import (
+	"github.com/hashicorp/hcl/v2"
+)
+
+// DecodeVariableBlock decodes the given "variable" block into a Variable,
+// which is used to validate a patched variable block.
+func DecodeVariableBlock(block *hcl.Block, override bool) (*Variable, hcl.Diagnostics) {
+	return decodeVariableBlock(block, override)
+}
+
+// DecodeOutputBlock decodes the given "output" block into an Output,
+// which is used to validate a patched output block.
+func DecodeOutputBlock(block *hcl.Block, override bool) (*Output, hcl.Diagnostics) {
+	return decodeOutputBlock(block, override)
+}
+
+// DecodeResourceBlock decodes the given "resource" block into a Resource,
+// which is used to validate a patched resource block.
+func DecodeResourceBlock(block *hcl.Block, override bool) (*Resource, hcl.Diagnostics) {
+	return decodeResourceBlock(block, override)
+}
+
+// DecodeDataBlock decodes the given "data" block into a Resource,
+// which is used to validate a patched data block.
+func DecodeDataBlock(block *hcl.Block, override, nested bool) (*Resource, hcl.Diagnostics) {
+	return decodeDataBlock(block, override, nested)
+}
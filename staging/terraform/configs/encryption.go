@@ -0,0 +1,20 @@
+package configs
+
+import (
+	"github.com/hashicorp/hcl/v2"
+)
+
+// Encryption represents an "encryption" block inside a "terraform" block in a module
+// or file, which is introduced by OpenTofu v1.7 to configure the state and plan encryption.
+type Encryption struct {
+	Config hcl.Body
+
+	DeclRange hcl.Range
+}
+
+func decodeEncryptionBlock(block *hcl.Block) (*Encryption, hcl.Diagnostics) {
+	return &Encryption{
+		Config:    block.Body,
+		DeclRange: block.DefRange,
+	}, nil
+}
@@ -16,6 +16,8 @@ type Import struct {
 	ProviderConfigRef *ProviderConfigRef
 	Provider          addrs.Provider
 
+	Config hcl.Body
+
 	DeclRange         hcl.Range
 	ProviderDeclRange hcl.Range
 }
@@ -23,6 +25,7 @@ type Import struct {
 func decodeImportBlock(block *hcl.Block) (*Import, hcl.Diagnostics) {
 	var diags hcl.Diagnostics
 	imp := &Import{
+		Config:    block.Body,
 		DeclRange: block.DefRange,
 	}
 
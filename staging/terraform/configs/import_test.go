@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hcltest"
 	"github.com/hashicorp/terraform/addrs"
@@ -160,7 +161,7 @@ func TestImportBlock_decode(t *testing.T) {
 				t.Fatal("expected error")
 			}
 
-			if !cmp.Equal(got, test.want, cmp.AllowUnexported(addrs.MoveEndpoint{})) {
+			if !cmp.Equal(got, test.want, cmp.AllowUnexported(addrs.MoveEndpoint{}), cmpopts.IgnoreFields(Import{}, "Config")) {
 				t.Fatalf("wrong result: %s", cmp.Diff(got, test.want))
 			}
 		})
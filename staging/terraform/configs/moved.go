@@ -12,12 +12,15 @@ type Moved struct {
 	From *addrs.MoveEndpoint
 	To   *addrs.MoveEndpoint
 
+	Config hcl.Body
+
 	DeclRange hcl.Range
 }
 
 func decodeMovedBlock(block *hcl.Block) (*Moved, hcl.Diagnostics) {
 	var diags hcl.Diagnostics
 	moved := &Moved{
+		Config:    block.Body,
 		DeclRange: block.DefRange,
 	}
 
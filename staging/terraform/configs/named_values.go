@@ -46,6 +46,7 @@ type Variable struct {
 	Nullable    bool
 	NullableSet bool
 
+	Config    hcl.Body
 	DeclRange hcl.Range
 	Tokens    hcl.Tokens
 }
@@ -53,6 +54,7 @@ type Variable struct {
 func decodeVariableBlock(block *hcl.Block, override bool) (*Variable, hcl.Diagnostics) {
 	v := &Variable{
 		Name:      block.Labels[0],
+		Config:    block.Body,
 		DeclRange: block.DefRange,
 		Tokens:    block.Tokens,
 	}
@@ -406,6 +408,7 @@ type Output struct {
 	DescriptionSet bool
 	SensitiveSet   bool
 
+	Config    hcl.Body
 	DeclRange hcl.Range
 	Tokens    hcl.Tokens
 }
@@ -415,6 +418,7 @@ func decodeOutputBlock(block *hcl.Block, override bool) (*Output, hcl.Diagnostic
 
 	o := &Output{
 		Name:      block.Labels[0],
+		Config:    block.Body,
 		DeclRange: block.DefRange,
 		Tokens:    block.Tokens,
 	}
@@ -27,6 +27,11 @@ type Parser struct {
 	// for itself whether to enable it so that tests can cover both the
 	// allowed and not-allowed situations.
 	allowExperiments bool
+
+	// allowTofuFiles controls whether we will read the .tofu and .tofu.json files
+	// in the same way as OpenTofu, which take precedence over the .tf and .tf.json files
+	// with the same name.
+	allowTofuFiles bool
 }
 
 // NewParser creates and returns a new Parser that reads files from the given
@@ -121,3 +126,11 @@ func (p *Parser) ForceFileSource(filename string, src []byte) {
 func (p *Parser) AllowLanguageExperiments(allowed bool) {
 	p.allowExperiments = allowed
 }
+
+// AllowOpenTofuFiles specifies whether the parser should read the .tofu and .tofu.json files
+// from the configuration directory, as OpenTofu does.
+//
+// If a .tofu or .tofu.json file exists, the .tf or .tf.json file with the same name is ignored.
+func (p *Parser) AllowOpenTofuFiles(allowed bool) {
+	p.allowTofuFiles = allowed
+}
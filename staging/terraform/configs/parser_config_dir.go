@@ -47,9 +47,24 @@ func (p *Parser) LoadConfigDir(path string) (*Module, hcl.Diagnostics) {
 
 	mod.SourceDir = path
 
+	sources := p.Sources()
+	for _, path := range primaryPaths {
+		mod.SourceFiles = append(mod.SourceFiles, SourceFile{Name: path, Bytes: sources[path]})
+	}
+	for _, path := range overridePaths {
+		mod.SourceFiles = append(mod.SourceFiles, SourceFile{Name: path, Bytes: sources[path], Override: true})
+	}
+
 	return mod, diags
 }
 
+// SourceFile describes the source of a configuration file loaded into a Module.
+type SourceFile struct {
+	Name     string
+	Bytes    []byte
+	Override bool
+}
+
 // ConfigDirFiles returns lists of the primary and override files configuration
 // files in the given directory.
 //
@@ -107,6 +122,9 @@ func (p *Parser) dirFiles(dir string) (primary, override []string, diags hcl.Dia
 
 		name := info.Name()
 		ext := fileExt(name)
+		if ext == "" && p.allowTofuFiles {
+			ext = tofuFileExt(name)
+		}
 		if ext == "" || IsIgnoredFile(name) {
 			continue
 		}
@@ -122,9 +140,40 @@ func (p *Parser) dirFiles(dir string) (primary, override []string, diags hcl.Dia
 		}
 	}
 
+	if p.allowTofuFiles {
+		primary = filterTofuAlternatives(primary)
+		override = filterTofuAlternatives(override)
+	}
+
 	return
 }
 
+// filterTofuAlternatives removes the .tf and .tf.json paths,
+// which have a .tofu or .tofu.json alternative in the given paths.
+func filterTofuAlternatives(paths []string) []string {
+	exists := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		exists[path] = true
+	}
+
+	filtered := make([]string, 0, len(paths))
+	for _, path := range paths {
+		switch fileExt(path) {
+		case ".tf":
+			if exists[strings.TrimSuffix(path, ".tf")+".tofu"] {
+				continue
+			}
+		case ".tf.json":
+			if exists[strings.TrimSuffix(path, ".tf.json")+".tofu.json"] {
+				continue
+			}
+		}
+		filtered = append(filtered, path)
+	}
+
+	return filtered
+}
+
 // fileExt returns the Terraform configuration extension of the given
 // path, or a blank string if it is not a recognized extension.
 func fileExt(path string) string {
@@ -137,6 +186,18 @@ func fileExt(path string) string {
 	}
 }
 
+// tofuFileExt returns the OpenTofu configuration extension of the given
+// path, or a blank string if it is not a recognized extension.
+func tofuFileExt(path string) string {
+	if strings.HasSuffix(path, ".tofu") {
+		return ".tofu"
+	} else if strings.HasSuffix(path, ".tofu.json") {
+		return ".tofu.json"
+	} else {
+		return ""
+	}
+}
+
 // IsIgnoredFile returns true if the given filename (which must not have a
 // directory path ahead of it) should be ignored as e.g. an editor swap file.
 func IsIgnoredFile(name string) bool {
@@ -0,0 +1,126 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package configs
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/terraform/addrs"
+)
+
+// Removed describes the "removed" block introduced by Terraform v1.7,
+// which records that an object has been removed from the configuration.
+type Removed struct {
+	From *addrs.MoveEndpoint
+
+	// Destroy indicates that the removed object should be destroyed,
+	// it is true unless the lifecycle block declares destroy = false.
+	Destroy bool
+
+	// Provisioners holds the destroy-time provisioners introduced by Terraform v1.9,
+	// which run when the removed object is destroyed.
+	Provisioners []*Provisioner
+	Connection   *Connection
+
+	Config hcl.Body
+
+	DeclRange hcl.Range
+}
+
+func decodeRemovedBlock(block *hcl.Block) (*Removed, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+	removed := &Removed{
+		Destroy:   true,
+		Config:    block.Body,
+		DeclRange: block.DefRange,
+	}
+
+	content, moreDiags := block.Body.Content(removedBlockSchema)
+	diags = append(diags, moreDiags...)
+
+	if attr, exists := content.Attributes["from"]; exists {
+		from, traversalDiags := hcl.AbsTraversalForExpr(attr.Expr)
+		diags = append(diags, traversalDiags...)
+		if !traversalDiags.HasErrors() {
+			from, fromDiags := addrs.ParseMoveEndpoint(from)
+			diags = append(diags, fromDiags.ToHCL()...)
+			removed.From = from
+		}
+	}
+
+	for _, block := range content.Blocks {
+		switch block.Type {
+		case "lifecycle":
+			lcContent, lcDiags := block.Body.Content(removedLifecycleBlockSchema)
+			diags = append(diags, lcDiags...)
+
+			if attr, exists := lcContent.Attributes["destroy"]; exists {
+				valDiags := gohcl.DecodeExpression(attr.Expr, nil, &removed.Destroy)
+				diags = append(diags, valDiags...)
+			}
+
+		case "connection":
+			if removed.Connection != nil {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Duplicate connection block",
+					Detail:   fmt.Sprintf("This removed block already has a connection block at %s.", removed.Connection.DeclRange),
+					Subject:  &block.DefRange,
+				})
+				continue
+			}
+
+			removed.Connection = &Connection{
+				Config:    block.Body,
+				DeclRange: block.DefRange,
+			}
+
+		case "provisioner":
+			pv, pvDiags := decodeProvisionerBlock(block)
+			diags = append(diags, pvDiags...)
+			if pv == nil {
+				continue
+			}
+
+			if pv.When != ProvisionerWhenDestroy {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid provisioner block",
+					Detail:   "Only destroy-time provisioners are allowed in removed blocks, set when = destroy.",
+					Subject:  &block.DefRange,
+				})
+				continue
+			}
+
+			removed.Provisioners = append(removed.Provisioners, pv)
+		}
+	}
+
+	return removed, diags
+}
+
+var removedBlockSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{
+			Name:     "from",
+			Required: true,
+		},
+	},
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "lifecycle"},
+		{Type: "connection"},
+		{Type: "provisioner", LabelNames: []string{"type"}},
+	},
+}
+
+var removedLifecycleBlockSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{
+			Name:     "destroy",
+			Required: true,
+		},
+	},
+}
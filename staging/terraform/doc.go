@@ -9,7 +9,12 @@
 //
 // Secondary, this package exposes the configs.MergeBody type,
 // which is used to merge the HCL bodies in the Terraform module,
-// and exposes the AST tokens of some Terraform blocks.
+// exposes the AST tokens of some Terraform blocks,
+// exposes the source files of the Terraform module,
+// exposes the decoders of some Terraform blocks,
+// supports the "removed" block introduced by Terraform v1.7,
+// supports the "encryption" block introduced by OpenTofu v1.7,
+// and supports the .tofu and .tofu.json files introduced by OpenTofu v1.8.
 //
 // This package is not exactly the same with the original implementation but works well for us.
 package terraform